@@ -0,0 +1,102 @@
+package formulate
+
+import (
+	"time"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// The elements that Tab can move to inside a dialog
+const focusable = "button, [href], input:not([type=hidden]), select, textarea, [tabindex]:not([tabindex='-1'])"
+
+// SetError shows a message under the field, and marks the field as invalid
+// for screen readers. An empty message clears the error
+func (f *EditForm) SetError(model string, message string) {
+	if message == "" {
+		f.ClearError(model)
+		return
+	}
+	if el := f.find(f.id(model)); el != nil {
+		el.SetAttribute("aria-invalid", "true")
+	}
+	if el := f.find(f.id(model + "-error")); el != nil {
+		el.SetTextContent(message)
+		setNodeClass(el, f.CurrentTheme().Class("hidden"), false)
+	}
+}
+
+// ClearError removes the error message from the field
+func (f *EditForm) ClearError(model string) {
+	if el := f.find(f.id(model)); el != nil {
+		el.RemoveAttribute("aria-invalid")
+	}
+	if el := f.find(f.id(model + "-error")); el != nil {
+		el.SetTextContent("")
+		setNodeClass(el, f.CurrentTheme().Class("hidden"), true)
+	}
+}
+
+// ClearErrors removes the error messages from every field on the form
+func (f *EditForm) ClearErrors() {
+	for _, el := range f.findAll("[aria-invalid]") {
+		el.RemoveAttribute("aria-invalid")
+	}
+	for _, el := range f.findAll(".field-error") {
+		el.SetTextContent("")
+		setNodeClass(el, f.CurrentTheme().Class("hidden"), true)
+	}
+}
+
+// Announce reads out a message to screen readers, such as the result of a save
+func (f *EditForm) Announce(message string) {
+	el := f.find(f.id("status"))
+	if el == nil {
+		return
+	}
+	// clear it first, so that the same message is announced again
+	el.SetTextContent("")
+	time.AfterFunc(100*time.Millisecond, func() {
+		el.SetTextContent(message)
+	})
+}
+
+// Elements with role=button are not buttons, so make Enter and Space click on them
+func activateButtons(l *listeners, root dom.Element) {
+	for _, el := range querySelectorAll(root, "[role=button]") {
+		btn, ok := el.(dom.HTMLElement)
+		if !ok {
+			continue
+		}
+		l.add(btn, "keydown", func(evt dom.Event) {
+			switch evt.(*dom.KeyboardEvent).Key {
+			case "Enter", " ":
+				evt.PreventDefault()
+				btn.Click()
+			}
+		})
+	}
+}
+
+// Keep Tab and Shift+Tab inside the dialog while it is showing
+func trapFocus(l *listeners, dialog dom.Element) {
+	l.add(dialog, "keydown", func(evt dom.Event) {
+		kevt := evt.(*dom.KeyboardEvent)
+		if kevt.Key != "Tab" {
+			return
+		}
+		els := dialog.QuerySelectorAll(focusable)
+		if len(els) == 0 {
+			return
+		}
+		first, last := els[0], els[len(els)-1]
+		active := dom.GetWindow().Document().(dom.HTMLDocument).ActiveElement()
+		switch {
+		case kevt.ShiftKey && (active == nil || dom.Equal(active.Underlying(), first.Underlying())):
+			evt.PreventDefault()
+			last.(dom.HTMLElement).Focus()
+		case !kevt.ShiftKey && (active == nil || dom.Equal(active.Underlying(), last.Underlying())):
+			evt.PreventDefault()
+			first.(dom.HTMLElement).Focus()
+		}
+	})
+}
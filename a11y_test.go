@@ -0,0 +1,43 @@
+package formulate
+
+import "testing"
+
+func TestSetErrorAria(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+
+	input := fakeField(t, doc, "#"+f.UID+"-Name")
+	errID := f.UID + "-Name-error"
+	if got := input.GetAttribute("aria-describedby"); got != errID {
+		t.Errorf("aria-describedby = %q, want %q", got, errID)
+	}
+	msg := fakeField(t, doc, "#"+errID)
+	hidden := f.CurrentTheme().Class("hidden")
+
+	f.SetError("Name", "Name is required")
+	if input.GetAttribute("aria-invalid") != "true" {
+		t.Error("the field is not marked invalid")
+	}
+	if msg.textContent() != "Name is required" || msg.HasClass(hidden) {
+		t.Errorf("error message = %q, hidden %v", msg.textContent(), msg.HasClass(hidden))
+	}
+
+	f.SetError("Hours", "Too many hours")
+	f.ClearError("Name")
+	if _, ok := input.attrs["aria-invalid"]; ok {
+		t.Error("the field is still marked invalid")
+	}
+	if msg.textContent() != "" || !msg.HasClass(hidden) {
+		t.Error("the error message is still shown")
+	}
+
+	f.ClearErrors()
+	if _, ok := fakeField(t, doc, "#"+f.UID+"-Hours").attrs["aria-invalid"]; ok {
+		t.Error("ClearErrors left Hours marked invalid")
+	}
+	if !fakeField(t, doc, "#"+f.UID+"-Hours-error").HasClass(hidden) {
+		t.Error("ClearErrors left the Hours message shown")
+	}
+}
@@ -0,0 +1,201 @@
+package formulate
+
+import (
+	"sort"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Breakpoint - a named range of window widths, from MinWidth up to the MinWidth of the next one
+type Breakpoint struct {
+	Name     string
+	MinWidth int
+}
+
+// Breakpoints that forms are laid out for, narrowest first. The narrowest one is
+// the mobile layout, which has short dates in lists
+var Breakpoints = []Breakpoint{
+	{Name: "mobile", MinWidth: 0},
+	{Name: "tablet", MinWidth: 740},
+	{Name: "desktop", MinWidth: 1024},
+}
+
+// SetBreakpoints changes the breakpoints that forms are laid out for
+func SetBreakpoints(breakpoints ...Breakpoint) {
+	if len(breakpoints) == 0 {
+		print("form: SetBreakpoints needs at least one breakpoint")
+		return
+	}
+	bps := append([]Breakpoint{}, breakpoints...)
+	sort.SliceStable(bps, func(i, j int) bool { return bps[i].MinWidth < bps[j].MinWidth })
+	Breakpoints = bps
+}
+
+// The width of the window, or 0 if there is no browser. Tests swap this for a fixed width
+var windowWidth = func() int {
+	if !hasDOM() {
+		return 0
+	}
+	return dom.GetWindow().InnerWidth()
+}
+
+// CurrentBreakpoint returns the name of the breakpoint for the width of the window.
+// Without a browser, such as when rendering on the server, it is the widest one
+func CurrentBreakpoint() string {
+	if len(Breakpoints) == 0 {
+		return ""
+	}
+	width := windowWidth()
+	if width <= 0 {
+		return Breakpoints[len(Breakpoints)-1].Name
+	}
+	name := Breakpoints[0].Name
+	for _, bp := range Breakpoints {
+		if width >= bp.MinWidth {
+			name = bp.Name
+		}
+	}
+	return name
+}
+
+// Whether the breakpoint is the narrowest one
+func isMobile(breakpoint string) bool {
+	return len(Breakpoints) > 0 && breakpoint == Breakpoints[0].Name
+}
+
+// SpanAt - the span of the row at the breakpoint, which is its Span unless it has another for that breakpoint
+func (r *EditRow) SpanAt(breakpoint string) int {
+	if span, ok := r.Spans[breakpoint]; ok {
+		return span
+	}
+	return r.Span
+}
+
+// SpanAt - the span of the field at the breakpoint, which is its Span unless it has another for that breakpoint
+func (e *EditField) SpanAt(breakpoint string) int {
+	if span, ok := e.Spans[breakpoint]; ok {
+		return span
+	}
+	return e.Span
+}
+
+// RowSpan sets the span of the row at a breakpoint, such as Row(3).RowSpan("mobile", 1)
+func (r *EditRow) RowSpan(breakpoint string, span int) *EditRow {
+	if r.Spans == nil {
+		r.Spans = map[string]int{}
+	}
+	r.Spans[breakpoint] = span
+	return r
+}
+
+// FieldSpan sets the span of the last field added to the row at a breakpoint
+func (r *EditRow) FieldSpan(breakpoint string, span int) *EditRow {
+	if f := r.last(); f != nil {
+		if f.Spans == nil {
+			f.Spans = map[string]int{}
+		}
+		f.Spans[breakpoint] = span
+	}
+	return r
+}
+
+// Breakpoint returns the breakpoint that the form was last laid out for
+func (f *EditForm) Breakpoint() string {
+	return f.breakpoint
+}
+
+// Lay the form out again if the window has changed to another breakpoint,
+// keeping the values that have been entered and the open swapper panels
+func (f *EditForm) relayout() {
+	bp := CurrentBreakpoint()
+	if bp == f.breakpoint || f.repaint == nil {
+		return
+	}
+	f.keepValues()
+	open := f.openPanels()
+	f.breakpoint = bp
+	f.paintAgain(open)
+}
+
+// Lay out the form again when the window is resized to another breakpoint
+func (f *EditForm) decorateResize() {
+	if !hasDOM() {
+		return
+	}
+	f.listeners.add(dom.GetWindow(), "resize", func(dom.Event) {
+		f.relayout()
+	})
+}
+
+// HideColumnAt hides the column of the model at the breakpoints
+func (f *ListForm) HideColumnAt(model string, breakpoints ...string) *ListForm {
+	if c := f.column(model); c != nil {
+		c.HideAt = append(c.HideAt, breakpoints...)
+	}
+	return f
+}
+
+// ColumnFormatAt sets the format of the column of the model at a breakpoint,
+// such as "short-date" for a date column on mobile
+func (f *ListForm) ColumnFormatAt(model string, breakpoint string, format string) *ListForm {
+	if c := f.column(model); c != nil {
+		if c.Formats == nil {
+			c.Formats = map[string]string{}
+		}
+		c.Formats[breakpoint] = format
+	}
+	return f
+}
+
+func (f *ListForm) column(model string) *ListCol {
+	for _, c := range f.Cols {
+		if c.Model == model {
+			return c
+		}
+	}
+	print("form: there is no column for", model)
+	return nil
+}
+
+// Whether the column is hidden at the breakpoint
+func (c *ListCol) hiddenAt(breakpoint string) bool {
+	for _, bp := range c.HideAt {
+		if bp == breakpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// The format of the column at the breakpoint. Dates are short in the mobile layout
+func (c *ListCol) formatAt(breakpoint string) string {
+	if format, ok := c.Formats[breakpoint]; ok {
+		return format
+	}
+	if c.Format == "date" && isMobile(breakpoint) {
+		return "short-date"
+	}
+	return c.Format
+}
+
+// Breakpoint returns the breakpoint that the list was last laid out for
+func (f *ListForm) Breakpoint() string {
+	return f.breakpoint
+}
+
+// Render the list again if the window has changed to another breakpoint
+func (f *ListForm) relayout() {
+	if bp := CurrentBreakpoint(); bp != f.breakpoint && f.rerender != nil {
+		f.rerender()
+	}
+}
+
+// Lay out the list again when the window is resized to another breakpoint
+func (f *ListForm) decorateResize() {
+	if !hasDOM() {
+		return
+	}
+	f.listeners.add(dom.GetWindow(), "resize", func(dom.Event) {
+		f.relayout()
+	})
+}
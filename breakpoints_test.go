@@ -0,0 +1,100 @@
+package formulate
+
+import "testing"
+
+// Lay forms out as if the window were this wide, until the test is done
+func setWindowWidth(t *testing.T, width int) {
+	saved := windowWidth
+	windowWidth = func() int { return width }
+	t.Cleanup(func() { windowWidth = saved })
+}
+
+func TestCurrentBreakpoint(t *testing.T) {
+	cases := map[int]string{0: "desktop", 320: "mobile", 739: "mobile", 740: "tablet", 1100: "desktop"}
+	for width, want := range cases {
+		setWindowWidth(t, width)
+		if got := CurrentBreakpoint(); got != want {
+			t.Errorf("width %d is %q, want %q", width, got, want)
+		}
+	}
+
+	saved := Breakpoints
+	defer func() { Breakpoints = saved }()
+	SetBreakpoints(Breakpoint{"wide", 1400}, Breakpoint{"narrow", 0})
+	setWindowWidth(t, 1000)
+	if got := CurrentBreakpoint(); got != "narrow" || !isMobile(got) {
+		t.Errorf("custom breakpoint = %q", got)
+	}
+}
+
+func TestEditFormBreakpointSpans(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	setWindowWidth(t, 1200)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1}
+	f := &EditForm{}
+	f.New("fa-wrench", "Job")
+	f.Row(3).RowSpan("mobile", 1).
+		AddInput(2, "Name", "Name").FieldSpan("mobile", 1).
+		AddNumber(1, "Hours", "Hours", "1")
+	f.Render("edit-form", "#form", job)
+
+	row := doc.QuerySelector("[data-row-span]").(*fakeNode)
+	name := fakeField(t, doc, `[name="Name"]`)
+	if f.Breakpoint() != "desktop" || row.attrs["data-row-span"] != "3" || name.parent.attrs["data-field-span"] != "2" {
+		t.Fatalf("desktop layout: %s row %v field %v", f.Breakpoint(), row.attrs, name.parent.attrs)
+	}
+	name.SetValue("Pump rebuild")
+
+	// turning the tablet does nothing until it crosses a breakpoint
+	setWindowWidth(t, 1100)
+	f.relayout()
+	if fakeField(t, doc, `[name="Name"]`) != name {
+		t.Error("the form was laid out again within a breakpoint")
+	}
+
+	setWindowWidth(t, 400)
+	f.relayout()
+	row = doc.QuerySelector("[data-row-span]").(*fakeNode)
+	name = fakeField(t, doc, `[name="Name"]`)
+	if f.Breakpoint() != "mobile" || row.attrs["data-row-span"] != "1" || name.parent.attrs["data-field-span"] != "1" {
+		t.Errorf("mobile layout: %s row %v field %v", f.Breakpoint(), row.attrs, name.parent.attrs)
+	}
+	if name.Value() != "Pump rebuild" {
+		t.Errorf("the value entered was lost, Name = %q", name.Value())
+	}
+}
+
+func TestListFormBreakpointColumns(t *testing.T) {
+	f := &ListForm{}
+	f.New("fa-list", "Jobs").
+		Column("Name", "Name").
+		DateColumn("Due", "Due").
+		BoolColumn("Urgent", "Urgent").
+		HideColumnAt("Urgent", "mobile", "tablet")
+
+	layout := func(width int) (headings []string, name string) {
+		setWindowWidth(t, width)
+		f.breakpoint = CurrentBreakpoint()
+		for _, c := range f.VisibleCols() {
+			headings = append(headings, c.Heading)
+		}
+		return headings, f.templateName("jobs")
+	}
+	desktop, desktopName := layout(1200)
+	mobile, mobileName := layout(400)
+	if len(desktop) != 3 || len(mobile) != 2 {
+		t.Errorf("columns: desktop %v mobile %v", desktop, mobile)
+	}
+	if desktopName == mobileName {
+		t.Errorf("both breakpoints use the template %q", desktopName)
+	}
+
+	due := f.Cols[1]
+	if due.formatAt("desktop") != "date" || due.formatAt("mobile") != "short-date" {
+		t.Errorf("Due is %q on the desktop and %q on mobile", due.formatAt("desktop"), due.formatAt("mobile"))
+	}
+	f.ColumnFormatAt("Due", "tablet", "short-date")
+	if got := due.formatAt("tablet"); got != "short-date" {
+		t.Errorf("tablet format = %q", got)
+	}
+}
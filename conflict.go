@@ -0,0 +1,363 @@
+package formulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Conflict - a field that differs between the values entered on the form and
+// the values saved on the server by someone else since the form was rendered
+type Conflict struct {
+	Model     string
+	Label     string
+	Original  string // the value when the form was rendered
+	Mine      string // the value entered on the form
+	Theirs    string // the value saved on the server
+	Clash     bool   // both have changed it, to different values
+	UseTheirs bool   // which value is kept when the conflict is resolved
+	field     *EditField
+	mine      fieldState
+	theirs    fieldState
+}
+
+// What is kept on a field for its value
+type fieldState struct {
+	Value    string
+	Display  string
+	Checked  bool
+	Selected int
+	Files    []FileField
+}
+
+// The saved record that the form is in conflict with
+type conflictState struct {
+	version   string
+	theirs    map[*EditField]fieldState
+	conflicts []*Conflict
+}
+
+var conflictTemplate = template.Must(template.New("conflict").Parse(`
+<p>{{.Message}}</p>
+<table class="{{.TableClass}} conflict-table">
+  <thead>
+    <tr><th scope="col">{{.Field}}</th><th scope="col">{{.Original}}</th><th scope="col">{{.Mine}}</th><th scope="col">{{.Theirs}}</th></tr>
+  </thead>
+  <tbody>
+    {{range $i, $c := .Conflicts}}
+    <tr{{if .Clash}} class="conflict-clash"{{end}}>
+      <th scope="row">{{.Label}}</th>
+      <td>{{.Original}}</td>
+      <td><label><input type="radio" name="{{$.UID}}-conflict-{{$i}}" value="mine" {{if not .UseTheirs}}checked{{end}}> {{.Mine}}</label></td>
+      <td><label><input type="radio" name="{{$.UID}}-conflict-{{$i}}" value="theirs" {{if .UseTheirs}}checked{{end}}> {{.Theirs}}</label></td>
+    </tr>
+    {{end}}
+  </tbody>
+</table>
+<button type="button" class="{{.ButtonClass}} conflict-resolve">{{.Resolve}}</button>`))
+
+// SetVersion names the model that holds the version or ETag of the data. The version is
+// remembered on Render and sent back by SubmitTo, and if the server replies that the record
+// has changed since, the form shows what has changed so that the user can choose what to keep
+func (f *EditForm) SetVersion(model string) *EditForm {
+	f.VersionModel = model
+	return f
+}
+
+// Remember the data, its version and the values that the form was rendered with
+func (f *EditForm) remember(data interface{}) {
+	f.data = data
+	f.watched = nil
+	if f.VersionModel != "" {
+		f.Version = versionOf(data, f.VersionModel)
+	}
+	f.dataType = reflect.TypeOf(data)
+	f.original = f.fieldStates()
+	f.conflict = nil
+}
+
+// The version held by the data, as a string, or "" if it has none
+func versionOf(data interface{}, model string) string {
+	v := modelValue(data, model)
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Put the version that the form was rendered with into the data that is sent
+func (f *EditForm) bindVersion(data interface{}) {
+	if f.VersionModel == "" || f.Version == "" {
+		return
+	}
+	if m, ok := dataMap(data); ok {
+		setMapValue(m, f.VersionModel, f.Version)
+		return
+	}
+	ptrVal := reflect.ValueOf(data)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() {
+		return
+	}
+	target := modelField(ptrVal, f.VersionModel, true)
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(f.Version)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, _ := strconv.ParseInt(f.Version, 10, 64)
+		target.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, _ := strconv.ParseUint(f.Version, 10, 64)
+		target.SetUint(i)
+	}
+}
+
+// The version as an If-Match header, which is a quoted entity tag
+func ifMatch(version string) string {
+	if strings.HasPrefix(version, `"`) || strings.HasPrefix(version, "W/") {
+		return version
+	}
+	return strconv.Quote(version)
+}
+
+// The version of the record in a reply, from the ETag or the data in the body
+func (f *EditForm) replyVersion(resp *http.Response, data interface{}) string {
+	if data != nil && f.VersionModel != "" {
+		if v := versionOf(data, f.VersionModel); v != "" {
+			return v
+		}
+	}
+	return etagVersion(resp.Header.Get("ETag"))
+}
+
+// The version in an ETag, without the quotes or the weak prefix, so that it can be
+// bound into the data. ifMatch quotes it again for the header
+func etagVersion(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) >= 2 && strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`) {
+		tag = tag[1 : len(tag)-1]
+	}
+	return tag
+}
+
+// Decode the record in a reply as the same type as the data the form was rendered with
+func (f *EditForm) decodeRecord(body []byte) interface{} {
+	var data interface{}
+	if t := f.dataType; t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		data = reflect.New(t.Elem()).Interface()
+	} else {
+		data = &map[string]interface{}{}
+	}
+	if len(bytes.TrimSpace(body)) == 0 || json.Unmarshal(body, data) != nil {
+		return nil
+	}
+	if m, ok := data.(*map[string]interface{}); ok {
+		return *m
+	}
+	return data
+}
+
+// What is kept on each field that has a model
+func (f *EditForm) fieldStates() map[*EditField]fieldState {
+	states := map[*EditField]fieldState{}
+	for _, field := range f.allFields() {
+		if field.Model == "" {
+			continue
+		}
+		states[field] = stateOf(field)
+	}
+	return states
+}
+
+// The value kept on a field
+func stateOf(field *EditField) fieldState {
+	return fieldState{
+		Value:    field.Value,
+		Display:  field.Display,
+		Checked:  field.Checked,
+		Selected: field.Selected,
+		Files:    field.Files,
+	}
+}
+
+// Put a kept value back on a field
+func setFieldState(field *EditField, s fieldState) {
+	field.Value = s.Value
+	field.Display = s.Display
+	field.Checked = s.Checked
+	field.Selected = s.Selected
+	field.Files = s.Files
+	switch field.Type {
+	case "select", "radio":
+		if key, err := strconv.Atoi(s.Value); err == nil {
+			field.selectKey(key)
+		}
+	case "groupselect":
+		if key, err := strconv.Atoi(s.Value); err == nil {
+			field.Selected = key
+		}
+	}
+}
+
+// Whether two kept values are the same value
+func sameState(field *EditField, a, b fieldState) bool {
+	if field.Type == "checkbox" {
+		return (a.Checked || a.Value != "") == (b.Checked || b.Value != "")
+	}
+	return a.Value == b.Value
+}
+
+// The text of a kept value, the way it reads on the form
+func stateText(field *EditField, s fieldState) string {
+	c := *field
+	c.Value, c.Checked, c.Selected = s.Value, s.Checked, s.Selected
+	return textOf(&c)
+}
+
+// Whether a field can be shown in the compare
+func comparesField(field *EditField) bool {
+	switch field.Type {
+	case "files", "signature", "photo", "div", "button", "swapper":
+		return false
+	}
+	return !field.IsHidden()
+}
+
+// Conflicts compares the values entered on the form with the record saved on the
+// server, and the values that the form was rendered with. Fields where only one
+// side has changed default to that change, and fields that both have changed keep
+// the value entered on the form
+func (f *EditForm) Conflicts(theirs interface{}) []*Conflict {
+	f.keepValues()
+	mine := f.fieldStates()
+	f.load(theirs)
+	saved := f.fieldStates()
+	for field, s := range mine {
+		setFieldState(field, s)
+	}
+	version := ""
+	if f.VersionModel != "" {
+		version = versionOf(theirs, f.VersionModel)
+	}
+	f.conflict = &conflictState{version: version, theirs: saved}
+
+	for _, field := range f.allFields() {
+		m, ok := mine[field]
+		if !ok || !comparesField(field) || field.Readonly {
+			continue
+		}
+		t := saved[field]
+		if sameState(field, m, t) {
+			continue
+		}
+		o, rendered := f.original[field]
+		if !rendered {
+			o = m
+		}
+		c := &Conflict{
+			Model:    field.Model,
+			Label:    field.Label,
+			Original: stateText(field, o),
+			Mine:     stateText(field, m),
+			Theirs:   stateText(field, t),
+			field:    field,
+			mine:     m,
+			theirs:   t,
+		}
+		c.UseTheirs = sameState(field, m, o)
+		c.Clash = !c.UseTheirs && !sameState(field, t, o)
+		f.conflict.conflicts = append(f.conflict.conflicts, c)
+	}
+	return f.conflict.conflicts
+}
+
+// ShowConflicts shows the compare of each conflict on the form, with a choice of
+// values to keep for each field, and a button that resolves them
+func (f *EditForm) ShowConflicts(conflicts []*Conflict) {
+	el := f.find(f.id("conflict"))
+	if el == nil {
+		return
+	}
+	t := f.CurrentTheme()
+	buf := &bytes.Buffer{}
+	err := conflictTemplate.Execute(buf, struct {
+		UID, Message, Field, Original, Mine, Theirs, Resolve string
+		TableClass, ButtonClass                              string
+		Conflicts                                            []*Conflict
+	}{
+		UID:         f.UID,
+		Message:     T("This record has been changed by someone else. Choose the values to keep, then save again"),
+		Field:       T("Field"),
+		Original:    T("Original"),
+		Mine:        T("Yours"),
+		Theirs:      T("Theirs"),
+		Resolve:     T("Use these values"),
+		TableClass:  t.Class("table"),
+		ButtonClass: t.Class("button-primary"),
+		Conflicts:   conflicts,
+	})
+	if err != nil {
+		print("ShowConflicts:", err.Error())
+		return
+	}
+	el.SetInnerHTML(buf.String())
+	el.RemoveAttribute("hidden")
+	if btn := el.QuerySelector(".conflict-resolve"); btn != nil {
+		f.listeners.addNode(btn, "click", func(evt dom.Event) {
+			f.ResolveConflicts()
+		})
+	}
+	f.Announce(T("This record has been changed by someone else"))
+}
+
+// ResolveConflicts keeps the value chosen for each conflict, takes the saved values
+// of fields that cannot be edited, and renders the form again ready to save over
+// the saved record
+func (f *EditForm) ResolveConflicts() {
+	if f.conflict == nil {
+		return
+	}
+	for i, c := range f.conflict.conflicts {
+		for _, radio := range f.findAll(`[name="` + f.UID + "-conflict-" + strconv.Itoa(i) + `"]`) {
+			if radio.Checked() {
+				c.UseTheirs = radio.Value() == "theirs"
+			}
+		}
+	}
+
+	f.keepValues()
+	open := f.openPanels()
+	for field, s := range f.conflict.theirs {
+		if field.Readonly {
+			setFieldState(field, s)
+		}
+	}
+	for _, c := range f.conflict.conflicts {
+		if c.UseTheirs {
+			setFieldState(c.field, c.theirs)
+		} else {
+			setFieldState(c.field, c.mine)
+		}
+	}
+	if f.conflict.version != "" {
+		f.Version = f.conflict.version
+	}
+	f.original = f.conflict.theirs
+	f.conflict = nil
+	if f.repaint != nil {
+		f.paintAgain(open)
+	}
+	f.Announce(T("Save again to keep these values"))
+}
@@ -0,0 +1,130 @@
+package formulate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type testTicket struct {
+	Version int
+	Title   string
+	Status  int
+	Urgent  bool
+	Notes   string
+}
+
+func newTestTicketForm(ticket *testTicket) *EditForm {
+	f := EditForm{}
+	f.New("fa-ticket", "Ticket").SetVersion("Version")
+	f.Row(3).
+		AddInput(1, "Title", "Title").
+		AddSelect(1, "Status", "Status", testStatuses, "ID", "Name", 1, ticket.Status).
+		AddCheck(1, "Urgent", "Urgent")
+	f.Row(1).
+		AddTextarea(1, "Notes", "Notes")
+	return &f
+}
+
+func TestSubmitToConflict(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	theirs := testTicket{Version: 4, Title: "Pump rebuild", Status: 2, Notes: "Check seals"}
+	var got []testTicket
+	version := theirs.Version
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sent testTicket
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+			return
+		}
+		got = append(got, sent)
+		if r.Header.Get("If-Match") != strconv.Quote(strconv.Itoa(version)) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(theirs)
+			return
+		}
+		version++
+		w.Header().Set("ETag", `W/"`+strconv.Itoa(version)+`"`)
+	}))
+	defer srv.Close()
+
+	ticket := &testTicket{Version: 3, Title: "Pump service", Status: 1, Notes: "Check seals"}
+	f := newTestTicketForm(ticket)
+	f.Render("edit-form", "#form", ticket)
+	if f.Version != "3" {
+		t.Fatalf("Version = %q", f.Version)
+	}
+	fakeField(t, doc, `[name="Title"]`).SetValue("Pump service and test")
+	fakeField(t, doc, `[name="Notes"]`).SetValue("Check seals and bearings")
+
+	err := f.SubmitTo(srv.URL, "PUT", SubmitOptions{Data: &testTicket{}})
+	serr, ok := err.(*SubmitError)
+	if !ok || serr.Status != http.StatusConflict {
+		t.Fatalf("err = %v", err)
+	}
+	if len(got) != 1 || got[0].Version != 3 {
+		t.Fatalf("server got %+v", got)
+	}
+	conflicts := map[string]*Conflict{}
+	for _, c := range serr.Conflicts {
+		conflicts[c.Model] = c
+	}
+	if len(conflicts) != 3 {
+		t.Fatalf("conflicts = %+v", serr.Conflicts)
+	}
+	if c := conflicts["Title"]; !c.Clash || c.UseTheirs || c.Original != "Pump service" || c.Mine != "Pump service and test" || c.Theirs != "Pump rebuild" {
+		t.Errorf("Title conflict = %+v", *c)
+	}
+	if c := conflicts["Status"]; c.Clash || !c.UseTheirs || c.Original != "Open" || c.Theirs != "Closed" {
+		t.Errorf("Status conflict = %+v", *c)
+	}
+	if c := conflicts["Notes"]; c.Clash || c.UseTheirs {
+		t.Errorf("Notes conflict = %+v", *c)
+	}
+
+	panel := fakeField(t, doc, "#"+f.UID+"-conflict")
+	if _, hidden := panel.attrs["hidden"]; hidden {
+		t.Error("the conflicts are not shown")
+	}
+	// take their title
+	for i, c := range serr.Conflicts {
+		if c.Model == "Title" {
+			for _, radio := range doc.QuerySelectorAll(`[name="` + f.UID + "-conflict-" + strconv.Itoa(i) + `"]`) {
+				radio.SetChecked(radio.Value() == "theirs")
+			}
+		}
+	}
+	for _, cb := range fakeField(t, doc, ".conflict-resolve").listeners["click"] {
+		cb(nil)
+	}
+	if f.Version != "4" {
+		t.Errorf("Version after resolving = %q", f.Version)
+	}
+	if _, hidden := fakeField(t, doc, "#"+f.UID+"-conflict").attrs["hidden"]; !hidden {
+		t.Error("the conflicts are still shown")
+	}
+
+	if err := f.SubmitTo(srv.URL, "PUT", SubmitOptions{Data: &testTicket{}}); err != nil {
+		t.Fatal(err)
+	}
+	want := testTicket{Version: 4, Title: "Pump rebuild", Status: 2, Notes: "Check seals and bearings"}
+	if len(got) != 2 || got[1] != want {
+		t.Errorf("server got %+v, want %+v", got, want)
+	}
+	if f.Version != "5" {
+		t.Errorf("Version after saving = %q", f.Version)
+	}
+
+	// the version from the ETag is sent with the next save
+	if err := f.SubmitTo(srv.URL, "PUT", SubmitOptions{Data: &testTicket{}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[2].Version != 5 {
+		t.Errorf("server got %+v", got)
+	}
+	if f.Version != "6" {
+		t.Errorf("Version after saving again = %q", f.Version)
+	}
+}
@@ -0,0 +1,380 @@
+package formulate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// datetime-local inputs leave the seconds off unless they are set
+const datetimeLocalLayout = "2006-01-02T15:04"
+
+// The field of the data that a model names. A model can be a dotted path
+// into nested structs, and each part matches the json tag of a field as
+// well as its name. Nil structs along the path are created if alloc is set
+func modelField(v reflect.Value, model string, alloc bool) reflect.Value {
+	parts := strings.Split(model, ".")
+	for i, name := range parts {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || i == 0 || !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		fld := v.FieldByName(name)
+		if !fld.IsValid() {
+			fld = fieldByTag(v, name)
+		}
+		v = fld
+	}
+	return v
+}
+
+// The value in the data that a model names, whether the data is a struct or a map
+func modelValue(data interface{}, model string) reflect.Value {
+	if m, ok := dataMap(data); ok {
+		v, _ := mapValue(m, model)
+		if v == nil {
+			return reflect.Value{}
+		}
+		return reflect.ValueOf(v)
+	}
+	return modelField(reflect.ValueOf(data), model, false)
+}
+
+// The field of the struct with the json name, or the same name in another case
+func fieldByTag(v reflect.Value, name string) reflect.Value {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if tag == name || (tag == "" && strings.EqualFold(sf.Name, name)) {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// The map data for Render and Bind, if that is what was passed
+func dataMap(data interface{}) (map[string]interface{}, bool) {
+	switch m := data.(type) {
+	case map[string]interface{}:
+		return m, m != nil
+	case *map[string]interface{}:
+		if m == nil {
+			return nil, false
+		}
+		if *m == nil {
+			*m = map[string]interface{}{}
+		}
+		return *m, true
+	}
+	return nil, false
+}
+
+// Look up a dotted path in nested maps
+func mapValue(m map[string]interface{}, model string) (interface{}, bool) {
+	parts := strings.Split(model, ".")
+	for _, name := range parts[:len(parts)-1] {
+		next, ok := m[name].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = next
+	}
+	v, ok := m[parts[len(parts)-1]]
+	return v, ok
+}
+
+// Set a dotted path in nested maps, adding maps along the way
+func setMapValue(m map[string]interface{}, model string, v interface{}) {
+	parts := strings.Split(model, ".")
+	for _, name := range parts[:len(parts)-1] {
+		next, ok := m[name].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[name] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = v
+}
+
+// Load the field values from map data, such as decoded JSON
+func (f *EditForm) loadMap(m map[string]interface{}) {
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if field.Model == "" {
+				continue
+			}
+			v, ok := mapValue(m, field.Model)
+			if !ok || v == nil {
+				continue
+			}
+			f.loadMapValue(field, v)
+		}
+	}
+}
+
+// Load a value from map data into a field, formatted the way the template shows it
+func (f *EditForm) loadMapValue(field *EditField, v interface{}) {
+	switch field.Type {
+	case "files":
+		field.Files = getFiles(reflect.ValueOf(v))
+		return
+	case "signature":
+		field.Value = getSignature(reflect.ValueOf(v))
+		return
+	case "photo":
+		return
+	case "checkbox":
+		b, _ := v.(bool)
+		field.Checked = b
+	case "select", "radio":
+		field.selectKey(enumKey(field, v))
+	case "date", "datetime-local":
+		field.Value = fmt.Sprint(v)
+		if t, err := time.Parse(time.RFC3339, field.Value); err == nil {
+			field.Value = t.Format(dateLayout(field))
+		}
+	default:
+		if list, ok := v.([]interface{}); ok {
+			field.Value = listValue(reflect.ValueOf(list))
+		} else {
+			field.Value = fmt.Sprint(v)
+		}
+	}
+	if f.DisplayMode {
+		field.Display = displayValue(field, reflect.ValueOf(v))
+	}
+}
+
+// Read the DOM values of each field into map data, such as for encoding as JSON
+func (f *EditForm) bindMap(m map[string]interface{}) {
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if field.Readonly || field.Model == "" || f.access(field) != Editable {
+				continue
+			}
+			f.bindMapField(m, field)
+		}
+	}
+}
+
+// Read the DOM value of a field into map data
+func (f *EditForm) bindMapField(m map[string]interface{}, field *EditField) {
+	value := f.fieldValue(field)
+	switch field.Type {
+	case "checkbox":
+		setMapValue(m, field.Model, value != "")
+	case "select":
+		el := f.find(`[name="` + field.Model + `"]`)
+		if el == nil {
+			return
+		}
+		idx := el.SelectedIndex()
+		if idx < 0 || idx >= len(field.Options) {
+			return
+		}
+		setMapValue(m, field.Model, field.optionValue(field.Options[idx].Key))
+	case "radio":
+		if key, err := strconv.Atoi(value); err == nil {
+			setMapValue(m, field.Model, field.optionValue(key))
+		}
+	case "number":
+		if value == "" {
+			setMapValue(m, field.Model, nil)
+		} else if field.IsFloat {
+			v, _ := strconv.ParseFloat(value, 64)
+			setMapValue(m, field.Model, v)
+		} else {
+			v, _ := strconv.Atoi(value)
+			setMapValue(m, field.Model, v)
+		}
+	case "datetime-local":
+		setMapValue(m, field.Model, f.datetimeValue(m, field.Model, value))
+	case "date":
+		if value == "" {
+			setMapValue(m, field.Model, nil)
+		} else {
+			setMapValue(m, field.Model, value)
+		}
+	case "text", "textarea":
+		if field.ListOf != "" {
+			setMapValue(m, field.Model, listItems(value, field.ListOf))
+		} else {
+			setMapValue(m, field.Model, field.bindText(value))
+		}
+	case "files":
+		setMapValue(m, field.Model, append([]FileField{}, field.Files...))
+	case "signature":
+		setMapValue(m, field.Model, field.Value)
+	case "photo":
+		if field.PhotoUpload {
+			setMapValue(m, field.Model, f.photoFile(field))
+		}
+	case "div", "swapper", "button":
+		// nothing to bind
+	default:
+		// other input types, such as password or tel, are text
+		if f.find(`[name="`+field.Model+`"]`) != nil {
+			setMapValue(m, field.Model, field.bindText(value))
+		}
+	}
+}
+
+// The value of a datetime-local input for map data. The input has no zone, so it is
+// taken in the zone of the time that was in the data, so that the instant does not
+// move, or else written as a local time without a zone
+func (f *EditForm) datetimeValue(m map[string]interface{}, model string, value string) interface{} {
+	t, ok := parseDate(value)
+	if !ok {
+		return nil
+	}
+	old, ok := mapValue(m, model)
+	if !ok {
+		if rendered, isMap := dataMap(f.data); isMap {
+			old, ok = mapValue(rendered, model)
+		}
+	}
+	if ok {
+		if s, ok := old.(string); ok {
+			if was, err := time.Parse(time.RFC3339, s); err == nil {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, was.Location())
+				return t.Format(time.RFC3339)
+			}
+		}
+	}
+	return t.Format(rfc3339DatetimeLocalLayout)
+}
+
+// The uploaded photo of a photo field
+func (f *EditForm) photoFile(field *EditField) FileField {
+	ff := FileField{}
+	if img := f.find(`[name="` + field.Model + `Preview"]`); img != nil {
+		ff.Data = img.GetAttribute("src")
+	}
+	if input := f.find(`[name="` + field.Model + `"]`); input != nil {
+		ff.Filename = input.Value()
+		if lastSlash := strings.LastIndex(ff.Filename, `\`); lastSlash > -1 {
+			ff.Filename = ff.Filename[lastSlash+1:]
+		}
+	}
+	return ff
+}
+
+// The option key for a value from the data, which may be an enum value
+func enumKey(field *EditField, v interface{}) int {
+	s := fmt.Sprint(v)
+	for i, e := range field.Enum {
+		if e == s {
+			return i
+		}
+	}
+	if field.Enum != nil {
+		return -1
+	}
+	key, _ := strconv.Atoi(s)
+	return key
+}
+
+// Select the option with the key
+func (e *EditField) selectKey(key int) {
+	e.Value = strconv.Itoa(key)
+	for _, o := range e.Options {
+		o.Selected = o.Key == key
+	}
+}
+
+// The value that an option key binds as, which is the enum value if there is one
+func (e *EditField) optionValue(key int) interface{} {
+	if e.Enum == nil {
+		return key
+	}
+	if key < 0 || key >= len(e.Enum) {
+		return nil
+	}
+	return e.Enum[key]
+}
+
+// The go layout for the value of a date input
+func dateLayout(field *EditField) string {
+	if field.Type == "datetime-local" {
+		return datetimeLocalLayout
+	}
+	return rfc3339DateLayout
+}
+
+// Parse the value of a date or datetime-local input
+func parseDate(str string) (time.Time, bool) {
+	for _, layout := range []string{rfc3339DateLayout, datetimeLocalLayout, rfc3339DatetimeLocalLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// A slice as the text of a list textarea, one item per line
+func listValue(v reflect.Value) string {
+	lines := []string{}
+	for i := 0; i < v.Len(); i++ {
+		lines = append(lines, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// The items of a list textarea as JSON values, skipping blank lines
+func listItems(str string, itemType string) []interface{} {
+	items := []interface{}{}
+	for _, line := range strings.Split(str, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		switch itemType {
+		case "integer":
+			v, _ := strconv.Atoi(line)
+			items = append(items, v)
+		case "number":
+			v, _ := strconv.ParseFloat(line, 64)
+			items = append(items, v)
+		case "boolean":
+			v, _ := strconv.ParseBool(line)
+			items = append(items, v)
+		default:
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// Set a slice from the text of a list textarea
+func setFromList(target reflect.Value, str string) {
+	if target.Kind() != reflect.Slice {
+		setFromString(target, str)
+		return
+	}
+	list := reflect.MakeSlice(target.Type(), 0, 0)
+	for _, line := range strings.Split(str, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		item := reflect.New(target.Type().Elem()).Elem()
+		setFromString(item, line)
+		list = reflect.Append(list, item)
+	}
+	target.Set(list)
+}
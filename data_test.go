@@ -0,0 +1,44 @@
+package formulate
+
+import "testing"
+
+func TestBindMapDatetimeKeepsZone(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f := &EditForm{}
+	f.New("fa-clock", "Visit")
+	f.Row(2).
+		Add(1, "Arrive", "datetime-local", "Arrive", "").
+		Add(1, "Leave", "datetime-local", "Leave", "")
+	f.Row(2).
+		Add(1, "Phone", "tel", "Phone", "").
+		Add(1, "PIN", "password", "PIN", "")
+	data := map[string]interface{}{"Arrive": "2024-01-02T10:00:00+10:00", "Phone": "555 0100"}
+	f.Render("edit-form", "#form", data)
+
+	if got := fakeField(t, doc, `[name="Arrive"]`).Value(); got != "2024-01-02T10:00" {
+		t.Errorf("Arrive shows %q", got)
+	}
+	fakeField(t, doc, `[name="Arrive"]`).SetValue("2024-01-02T11:30")
+	fakeField(t, doc, `[name="Leave"]`).SetValue("2024-01-02T12:00")
+	fakeField(t, doc, `[name="PIN"]`).SetValue("1234")
+
+	f.Bind(data)
+	want := map[string]interface{}{
+		"Arrive": "2024-01-02T11:30:00+10:00",
+		"Leave":  "2024-01-02T12:00:00",
+		"Phone":  "555 0100",
+		"PIN":    "1234",
+	}
+	for model, v := range want {
+		if data[model] != v {
+			t.Errorf("%s bound as %v, want %v", model, data[model], v)
+		}
+	}
+
+	// bound into a fresh map, the zone comes from the data the form was rendered with
+	fresh := map[string]interface{}{}
+	f.Bind(fresh)
+	if fresh["Arrive"] != "2024-01-02T11:30:00+10:00" {
+		t.Errorf("Arrive bound as %v", fresh["Arrive"])
+	}
+}
@@ -0,0 +1,559 @@
+package formulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// DefinitionVersion is the version of the JSON that form definitions
+// are marshalled to. Definitions from a newer version are refused
+const DefinitionVersion = 1
+
+var callbacks = map[string]interface{}{}
+
+// RegisterCallback names a callback, so that forms loaded from JSON can be wired
+// up to it. Form events take a func(dom.Event), the attach event a func(),
+// the readprogress event a func(string, string, int, int), and listform rows a func(string)
+func RegisterCallback(name string, cb interface{}) {
+	callbacks[name] = cb
+}
+
+type formDef struct {
+	Version     int               `json:"version"`
+	Title       string            `json:"title,omitempty"`
+	Icon        string            `json:"icon,omitempty"`
+	DisplayMode bool              `json:"displayMode,omitempty"`
+	Live        bool              `json:"live,omitempty"`
+	Events      map[string]string `json:"events,omitempty"`
+	Rows        []rowDef          `json:"rows"`
+}
+
+type rowDef struct {
+	Span   int            `json:"span"`
+	Spans  map[string]int `json:"spans,omitempty"`
+	Fields []fieldDef     `json:"fields"`
+}
+
+type fieldDef struct {
+	Type        string         `json:"type"`
+	Span        int            `json:"span"`
+	Spans       map[string]int `json:"spans,omitempty"`
+	Label       string         `json:"label,omitempty"`
+	Model       string         `json:"model,omitempty"`
+	Readonly    bool           `json:"readonly,omitempty"`
+	Focus       bool           `json:"focus,omitempty"`
+	Autofocus   bool           `json:"autofocus,omitempty"`
+	Class       string         `json:"class,omitempty"`
+	Extras      string         `json:"extras,omitempty"`
+	Step        string         `json:"step,omitempty"`
+	Float       bool           `json:"float,omitempty"`
+	Decimals    int            `json:"decimals,omitempty"`
+	Options     []optionDef    `json:"options,omitempty"`
+	Groups      []groupDef     `json:"groups,omitempty"`
+	Selected    int            `json:"selected,omitempty"`
+	CodeBlock   bool           `json:"codeBlock,omitempty"`
+	BigText     bool           `json:"bigText,omitempty"`
+	Upload      bool           `json:"upload,omitempty"`
+	Preview     bool           `json:"preview,omitempty"`
+	Thumbnail   bool           `json:"thumbnail,omitempty"`
+	Photo       *photoDef      `json:"photo,omitempty"`
+	Accept      string         `json:"accept,omitempty"`
+	MaxFiles    int            `json:"maxFiles,omitempty"`
+	MaxSize     int            `json:"maxSize,omitempty"`
+	Swapper     *swapperDef    `json:"swapper,omitempty"`
+	Rules       *rulesDef      `json:"rules,omitempty"`
+	Enum        []string       `json:"enum,omitempty"`
+	ListOf      string         `json:"listOf,omitempty"`
+	Placeholder string         `json:"placeholder,omitempty"`
+	Help        string         `json:"help,omitempty"`
+	Tooltip     string         `json:"tooltip,omitempty"`
+	Mask        string         `json:"mask,omitempty"`
+	MaskRaw     bool           `json:"maskRaw,omitempty"`
+}
+
+type rulesDef struct {
+	Required  bool     `json:"required,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MinLength int      `json:"minLength,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+type optionDef struct {
+	Key      int    `json:"key"`
+	Display  string `json:"display"`
+	Selected bool   `json:"selected,omitempty"`
+}
+
+type groupDef struct {
+	Title   string      `json:"title,omitempty"`
+	Options []optionDef `json:"options"`
+}
+
+type photoDef struct {
+	MaxWidth       int     `json:"maxWidth,omitempty"`
+	MaxHeight      int     `json:"maxHeight,omitempty"`
+	Quality        float64 `json:"quality,omitempty"`
+	Format         string  `json:"format,omitempty"`
+	FixOrientation bool    `json:"fixOrientation,omitempty"`
+}
+
+type swapperDef struct {
+	Name   string     `json:"name"`
+	Panels []panelDef `json:"panels"`
+}
+
+type panelDef struct {
+	Name         string   `json:"name"`
+	BindWithForm bool     `json:"bindWithForm"`
+	Rows         []rowDef `json:"rows"`
+}
+
+type listDef struct {
+	Version   int               `json:"version"`
+	Title     string            `json:"title,omitempty"`
+	Icon      string            `json:"icon,omitempty"`
+	MaxChars  int               `json:"maxChars,omitempty"`
+	Draggable bool              `json:"draggable,omitempty"`
+	Events    map[string]string `json:"events,omitempty"`
+	Columns   []columnDef       `json:"columns"`
+}
+
+type columnDef struct {
+	Heading   string            `json:"heading"`
+	Model     string            `json:"model"`
+	Format    string            `json:"format,omitempty"`
+	Width     string            `json:"width,omitempty"`
+	Img       bool              `json:"img,omitempty"`
+	Array     bool              `json:"array,omitempty"`
+	Fieldname string            `json:"fieldname,omitempty"`
+	Bool      bool              `json:"bool,omitempty"`
+	MaxChars  int               `json:"maxChars,omitempty"`
+	Icon      bool              `json:"icon,omitempty"`
+	Edit      bool              `json:"edit,omitempty"`
+	HideAt    []string          `json:"hideAt,omitempty"`
+	Formats   map[string]string `json:"formats,omitempty"`
+}
+
+// MarshalJSON writes the layout of the editform, with its callbacks by name.
+// Only callbacks that were attached with On have a name to write
+func (f *EditForm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formDef{
+		Version:     DefinitionVersion,
+		Title:       f.Title,
+		Icon:        f.Icon,
+		DisplayMode: f.DisplayMode,
+		Live:        f.Live,
+		Events:      f.events,
+		Rows:        rowDefs(f.Rows),
+	})
+}
+
+// UnmarshalJSON loads the layout of the editform, attaching the named callbacks
+// from the registry
+func (f *EditForm) UnmarshalJSON(data []byte) error {
+	def := formDef{}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return err
+	}
+	if err := checkVersion(def.Version); err != nil {
+		return err
+	}
+	f.New(def.Icon, def.Title)
+	f.DisplayMode = def.DisplayMode
+	f.Live = def.Live
+	rows, err := editRows(def.Rows)
+	if err != nil {
+		return err
+	}
+	f.Rows = rows
+	for event, name := range def.Events {
+		if err := f.on(event, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the columns of the listform, with its callbacks by name.
+// Only callbacks that were attached with On have a name to write
+func (f *ListForm) MarshalJSON() ([]byte, error) {
+	def := listDef{
+		Version:   DefinitionVersion,
+		Title:     f.Title,
+		Icon:      f.Icon,
+		MaxChars:  f.MaxChars,
+		Draggable: f.Draggable,
+		Events:    f.events,
+		Columns:   []columnDef{},
+	}
+	for _, c := range f.Cols {
+		def.Columns = append(def.Columns, columnDef{
+			Heading:   c.Heading,
+			Model:     c.Model,
+			Format:    c.Format,
+			Width:     c.Width,
+			Img:       c.IsImg,
+			Array:     c.IsArray,
+			Fieldname: c.Fieldname,
+			Bool:      c.IsBool,
+			MaxChars:  c.MaxChars,
+			Icon:      c.IsIcon,
+			Edit:      c.CanEdit,
+			HideAt:    c.HideAt,
+			Formats:   c.Formats,
+		})
+	}
+	return json.Marshal(def)
+}
+
+// UnmarshalJSON loads the columns of the listform, attaching the named callbacks
+// from the registry
+func (f *ListForm) UnmarshalJSON(data []byte) error {
+	def := listDef{}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return err
+	}
+	if err := checkVersion(def.Version); err != nil {
+		return err
+	}
+	f.New(def.Icon, def.Title)
+	if def.MaxChars > 0 {
+		f.MaxChars = def.MaxChars
+	}
+	f.Draggable = def.Draggable
+	f.Cols = nil
+	f.HasSetWidth = false
+	f.HasImages = false
+	for _, c := range def.Columns {
+		f.Cols = append(f.Cols, &ListCol{
+			Heading:   c.Heading,
+			Model:     c.Model,
+			Format:    c.Format,
+			Width:     c.Width,
+			IsImg:     c.Img,
+			IsArray:   c.Array,
+			Fieldname: c.Fieldname,
+			IsBool:    c.Bool,
+			MaxChars:  c.MaxChars,
+			IsIcon:    c.Icon,
+			CanEdit:   c.Edit,
+			HideAt:    c.HideAt,
+			Formats:   c.Formats,
+		})
+		if c.Width != "" {
+			f.HasSetWidth = true
+		}
+		if c.Img || c.Bool || c.Icon || c.Edit {
+			f.HasImages = true
+		}
+	}
+	for event, name := range def.Events {
+		if err := f.on(event, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkVersion(v int) error {
+	if v < 1 || v > DefinitionVersion {
+		return fmt.Errorf("unsupported form definition version %d", v)
+	}
+	return nil
+}
+
+func rowDefs(rows []*EditRow) []rowDef {
+	defs := []rowDef{}
+	for _, r := range rows {
+		def := rowDef{Span: r.Span, Spans: r.Spans, Fields: []fieldDef{}}
+		for _, f := range r.Fields {
+			def.Fields = append(def.Fields, fieldDefOf(f))
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+func fieldDefOf(f *EditField) fieldDef {
+	def := fieldDef{
+		Type:        f.Type,
+		Span:        f.Span,
+		Spans:       f.Spans,
+		Label:       f.Label,
+		Model:       f.Model,
+		Readonly:    f.Readonly && !f.locked,
+		Focus:       f.Focusme,
+		Autofocus:   f.Autofocus,
+		Class:       f.Class,
+		Extras:      string(f.Extras),
+		Step:        f.Step,
+		Float:       f.IsFloat,
+		Decimals:    f.Decimals,
+		Selected:    f.Selected,
+		CodeBlock:   f.CodeBlock,
+		BigText:     f.BigText,
+		Upload:      f.PhotoUpload,
+		Preview:     f.Preview,
+		Thumbnail:   f.Thumbnail,
+		Accept:      f.Accept,
+		MaxFiles:    f.MaxFiles,
+		MaxSize:     f.MaxSize,
+		Enum:        f.Enum,
+		ListOf:      f.ListOf,
+		Placeholder: f.Placeholder,
+		Help:        f.Help,
+		Tooltip:     f.Tooltip,
+		Mask:        f.Mask,
+		MaskRaw:     f.MaskRaw,
+	}
+	if r := f.Rules; r != nil {
+		def.Rules = &rulesDef{
+			Required:  r.Required,
+			Min:       r.Min,
+			Max:       r.Max,
+			MinLength: r.MinLength,
+			MaxLength: r.MaxLength,
+			Pattern:   r.Pattern,
+			Message:   r.Message,
+		}
+	}
+	for _, o := range f.Options {
+		def.Options = append(def.Options, optionDef{Key: o.Key, Display: o.Display, Selected: o.Selected})
+	}
+	for _, g := range f.Group {
+		gd := groupDef{Title: g.Title, Options: []optionDef{}}
+		for _, o := range g.Options {
+			gd.Options = append(gd.Options, optionDef{Key: o.ID, Display: o.Name})
+		}
+		def.Groups = append(def.Groups, gd)
+	}
+	if o := f.PhotoOpts; o != nil {
+		def.Photo = &photoDef{
+			MaxWidth:       o.MaxWidth,
+			MaxHeight:      o.MaxHeight,
+			Quality:        o.Quality,
+			Format:         o.Format,
+			FixOrientation: o.FixOrientation,
+		}
+	}
+	if s := f.Swapper; s != nil {
+		def.Swapper = &swapperDef{Name: s.Name, Panels: []panelDef{}}
+		for _, p := range s.Panels {
+			def.Swapper.Panels = append(def.Swapper.Panels, panelDef{
+				Name:         p.Name,
+				BindWithForm: p.BindWithForm,
+				Rows:         rowDefs(p.Rows),
+			})
+		}
+	}
+	return def
+}
+
+func editRows(defs []rowDef) ([]*EditRow, error) {
+	rows := []*EditRow{}
+	for _, def := range defs {
+		r := &EditRow{Span: def.Span, Spans: def.Spans}
+		for _, fd := range def.Fields {
+			f, err := editFieldOf(fd)
+			if err != nil {
+				return nil, err
+			}
+			r.Fields = append(r.Fields, f)
+		}
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+func editFieldOf(def fieldDef) (*EditField, error) {
+	if def.Type == "" {
+		return nil, fmt.Errorf("field %q has no type", def.Model)
+	}
+	f := &EditField{
+		Type:        def.Type,
+		Span:        def.Span,
+		Spans:       def.Spans,
+		Label:       def.Label,
+		Model:       def.Model,
+		Readonly:    def.Readonly,
+		Focusme:     def.Focus,
+		Autofocus:   def.Autofocus,
+		Class:       def.Class,
+		Extras:      template.CSS(def.Extras),
+		Step:        def.Step,
+		IsFloat:     def.Float,
+		Decimals:    def.Decimals,
+		Selected:    def.Selected,
+		CodeBlock:   def.CodeBlock,
+		BigText:     def.BigText,
+		PhotoUpload: def.Upload,
+		Preview:     def.Preview,
+		Thumbnail:   def.Thumbnail,
+		Accept:      def.Accept,
+		MaxFiles:    def.MaxFiles,
+		MaxSize:     def.MaxSize,
+		Enum:        def.Enum,
+		ListOf:      def.ListOf,
+		Placeholder: def.Placeholder,
+		Help:        def.Help,
+		Tooltip:     def.Tooltip,
+		Mask:        def.Mask,
+		MaskRaw:     def.MaskRaw,
+	}
+	if r := def.Rules; r != nil {
+		f.Rules = &Rules{
+			Required:  r.Required,
+			Min:       r.Min,
+			Max:       r.Max,
+			MinLength: r.MinLength,
+			MaxLength: r.MaxLength,
+			Pattern:   r.Pattern,
+			Message:   r.Message,
+		}
+	}
+	for _, o := range def.Options {
+		f.Options = append(f.Options, &EditOption{Key: o.Key, Display: o.Display, Selected: o.Selected})
+	}
+	for _, gd := range def.Groups {
+		g := SelectGroup{Title: gd.Title}
+		for _, o := range gd.Options {
+			g.Options = append(g.Options, SelectOption{ID: o.Key, Name: o.Display})
+		}
+		f.Group = append(f.Group, g)
+	}
+	if o := def.Photo; o != nil {
+		f.PhotoOpts = &PhotoOptions{
+			MaxWidth:       o.MaxWidth,
+			MaxHeight:      o.MaxHeight,
+			Quality:        o.Quality,
+			Format:         o.Format,
+			FixOrientation: o.FixOrientation,
+		}
+	}
+	if def.Type == "swapper" {
+		if def.Swapper == nil {
+			return nil, fmt.Errorf("swapper field %q has no panels", def.Label)
+		}
+		f.Swapper = &Swapper{Name: def.Swapper.Name}
+		for _, pd := range def.Swapper.Panels {
+			rows, err := editRows(pd.Rows)
+			if err != nil {
+				return nil, err
+			}
+			f.Swapper.Panels = append(f.Swapper.Panels, &Panel{
+				Name:         pd.Name,
+				BindWithForm: pd.BindWithForm,
+				Rows:         rows,
+			})
+		}
+	}
+	return f, nil
+}
+
+// On attaches the named callback from the registry to an event of the editform:
+// cancel, delete, save, print, change, attach or readprogress.
+// The name is kept, so that it is written out by MarshalJSON
+func (f *EditForm) On(event string, name string) *EditForm {
+	if err := f.on(event, name); err != nil {
+		print(err.Error())
+	}
+	return f
+}
+
+func (f *EditForm) on(event string, name string) error {
+	cb, ok := callbacks[name]
+	if !ok {
+		return fmt.Errorf("no callback registered as %q", name)
+	}
+	wrongType := fmt.Errorf("callback %q is the wrong type for the %s event", name, event)
+	switch event {
+	case "cancel", "delete", "save", "print", "change":
+		c, ok := cb.(func(dom.Event))
+		if !ok {
+			return wrongType
+		}
+		switch event {
+		case "cancel":
+			f.CancelCB = c
+		case "delete":
+			f.DeleteCB = c
+		case "save":
+			f.SaveCB = c
+		case "print":
+			f.PrintCB = c
+		case "change":
+			f.ChangeCB = c
+		}
+	case "attach":
+		c, ok := cb.(func())
+		if !ok {
+			return wrongType
+		}
+		f.AttachCB = c
+	case "readprogress":
+		c, ok := cb.(func(string, string, int, int))
+		if !ok {
+			return wrongType
+		}
+		f.ReadProgressCB = c
+	default:
+		return fmt.Errorf("editform has no %s event", event)
+	}
+	if f.events == nil {
+		f.events = map[string]string{}
+	}
+	f.events[event] = name
+	return nil
+}
+
+// On attaches the named callback from the registry to an event of the listform:
+// cancel, newrow, print or row.
+// The name is kept, so that it is written out by MarshalJSON
+func (f *ListForm) On(event string, name string) *ListForm {
+	if err := f.on(event, name); err != nil {
+		print(err.Error())
+	}
+	return f
+}
+
+func (f *ListForm) on(event string, name string) error {
+	cb, ok := callbacks[name]
+	if !ok {
+		return fmt.Errorf("no callback registered as %q", name)
+	}
+	wrongType := fmt.Errorf("callback %q is the wrong type for the %s event", name, event)
+	switch event {
+	case "cancel", "newrow", "print":
+		c, ok := cb.(func(dom.Event))
+		if !ok {
+			return wrongType
+		}
+		switch event {
+		case "cancel":
+			f.CancelCB = c
+		case "newrow":
+			f.NewRowCB = c
+		case "print":
+			f.PrintCB = c
+		}
+	case "row":
+		c, ok := cb.(func(string))
+		if !ok {
+			return wrongType
+		}
+		f.RowCB = c
+	default:
+		return fmt.Errorf("listform has no %s event", event)
+	}
+	if f.events == nil {
+		f.events = map[string]string{}
+	}
+	f.events[event] = name
+	return nil
+}
@@ -0,0 +1,125 @@
+package formulate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+func TestEditFormJSONRoundTrip(t *testing.T) {
+	saved := 0
+	RegisterCallback("testSave", func(dom.Event) { saved++ })
+	RegisterCallback("testAttach", func() {})
+
+	f := newTestForm(&testJob{Status: 2, Group: 1, Priority: 3})
+	f.GetField("Photo").PhotoOpts = &PhotoOptions{MaxWidth: 800, Quality: 0.7}
+	f.On("save", "testSave").On("attach", "testAttach")
+	f.Rows[0].RowSpan("phone", 1).FieldSpan("phone", 1)
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"version":1`) {
+		t.Errorf("no version in %s", data)
+	}
+	if !strings.Contains(string(data), `"spans":{"phone":1}`) {
+		t.Errorf("no breakpoint spans in %s", data)
+	}
+
+	loaded := &EditForm{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("definition changed on reload\n got %s\nwant %s", again, data)
+	}
+	if !reflect.DeepEqual(loaded.Rows, f.Rows) {
+		t.Errorf("rows changed on reload")
+	}
+
+	if loaded.SaveCB == nil || loaded.AttachCB == nil {
+		t.Fatal("callbacks were not reattached")
+	}
+	loaded.SaveCB(nil)
+	if saved != 1 {
+		t.Errorf("save callback called %d times, want 1", saved)
+	}
+}
+
+func TestEditFormJSONLeavesOutLocks(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1}
+	f := newTestForm(job).SetPolicy(NewPolicy("clerk").Field("Notes", ReadOnly))
+	f.Render("edit-form", "#form", job)
+	f.SetDisplayMode(true)
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &EditForm{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	for model, want := range map[string]bool{"Name": false, "Notes": false, "Status": false, "Ref": true} {
+		if got := loaded.GetField(model).Readonly; got != want {
+			t.Errorf("%s readonly = %v after reload, want %v", model, got, want)
+		}
+	}
+}
+
+func TestEditFormJSONErrors(t *testing.T) {
+	RegisterCallback("testRow", func(string) {})
+
+	for _, tc := range []struct{ name, json string }{
+		{"no version", `{"rows":[]}`},
+		{"newer version", `{"version":99,"rows":[]}`},
+		{"no type", `{"version":1,"rows":[{"span":1,"fields":[{"span":1,"model":"Name"}]}]}`},
+		{"unknown callback", `{"version":1,"events":{"save":"nothingHere"},"rows":[]}`},
+		{"wrong callback type", `{"version":1,"events":{"save":"testRow"},"rows":[]}`},
+		{"unknown event", `{"version":1,"events":{"explode":"testRow"},"rows":[]}`},
+	} {
+		if err := json.Unmarshal([]byte(tc.json), &EditForm{}); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestListFormJSONRoundTrip(t *testing.T) {
+	RegisterCallback("testOpenRow", func(string) {})
+
+	f := &ListForm{}
+	f.New("fa-list", "Jobs").
+		Column("Name", "Name").
+		DateColumn("Due", "Due").
+		BoolColumn("Urgent", "Urgent").
+		On("row", "testOpenRow")
+	f.SetWidths([]string{"50%", "30%", "20%"})
+	f.HideColumnAt("Urgent", "phone", "tablet").ColumnFormatAt("Due", "phone", "02 Jan")
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &ListForm{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Cols, f.Cols) {
+		t.Errorf("columns changed on reload\n got %+v\nwant %+v", loaded.Cols, f.Cols)
+	}
+	if due := loaded.column("Due"); due.formatAt("phone") != "02 Jan" || !loaded.column("Urgent").hiddenAt("tablet") {
+		t.Errorf("breakpoints not restored: %+v", loaded.Cols)
+	}
+	if loaded.Title != "Jobs" || !loaded.HasSetWidth || !loaded.HasImages || loaded.RowCB == nil {
+		t.Errorf("listform not restored: %+v", loaded)
+	}
+}
@@ -0,0 +1,174 @@
+package formulate
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// SetDisplayMode switches a rendered form between display mode and edit mode,
+// keeping the values that have been entered but not saved, the open swapper
+// panels and the scroll position. Before the form is rendered, it just sets DisplayMode
+func (f *EditForm) SetDisplayMode(display bool) {
+	if f.DisplayMode == display {
+		return
+	}
+	if !f.IsRendered || f.repaint == nil {
+		f.DisplayMode = display
+		return
+	}
+
+	f.keepValues()
+	open := f.openPanels()
+	f.DisplayMode = display
+	for _, field := range f.allFields() {
+		f.lockField(field)
+		if display {
+			field.Display = displayOf(field)
+		}
+	}
+	f.paintAgain(open)
+}
+
+// Render the form again from the values kept on its fields, then open the swapper panels again
+func (f *EditForm) paintAgain(open map[*Swapper]int) {
+	f.repaint()
+	for s, idx := range open {
+		s.Select(idx)
+	}
+}
+
+// Every field on the form, including those on all the swapper panels
+func (f *EditForm) allFields() []*EditField {
+	fields := []*EditField{}
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			fields = append(fields, field)
+			if field.Type == "swapper" && field.Swapper != nil {
+				for _, p := range field.Swapper.Panels {
+					fields = append(fields, p.fields()...)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// The fields on the panel
+func (p *Panel) fields() []*EditField {
+	fields := []*EditField{}
+	for _, r := range p.Rows {
+		fields = append(fields, r.Fields...)
+	}
+	return fields
+}
+
+// The swapper panel that the field is on, or nil if it is not on one
+func (f *EditForm) panelOf(field *EditField) *Panel {
+	for _, row := range f.Rows {
+		for _, fld := range row.Fields {
+			if fld.Type != "swapper" || fld.Swapper == nil {
+				continue
+			}
+			for _, p := range fld.Swapper.Panels {
+				for _, pf := range p.fields() {
+					if pf == field {
+						return p
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// The panel that is showing on each swapper
+func (f *EditForm) openPanels() map[*Swapper]int {
+	open := map[*Swapper]int{}
+	for _, field := range f.allFields() {
+		s := field.Swapper
+		if field.Type != "swapper" || s == nil {
+			continue
+		}
+		for i, p := range s.Panels {
+			if el := s.query(`[name="` + s.Name + "-" + p.Name + `"]`); el != nil && el.GetAttribute("aria-hidden") == "" {
+				open[s] = i
+			}
+		}
+	}
+	return open
+}
+
+// Read the values in the DOM back into the fields, so that they are
+// rendered again with what has been entered
+func (f *EditForm) keepValues() {
+	for _, field := range f.allFields() {
+		f.keepValue(field)
+	}
+}
+
+// Read the value in the DOM back into the field
+func (f *EditForm) keepValue(field *EditField) {
+	if field.Readonly || field.Model == "" {
+		return
+	}
+	switch field.Type {
+	case "files", "signature", "div", "swapper", "button":
+		// already kept on the field
+		return
+	case "photo":
+		if field.PhotoUpload {
+			field.Value = f.photoFile(field).Data
+		}
+		return
+	case "radio":
+		if key, err := strconv.Atoi(f.fieldValue(field)); err == nil {
+			field.selectKey(key)
+		}
+		return
+	}
+	el := f.find(`[name="` + field.Model + `"]`)
+	if el == nil {
+		return
+	}
+	switch field.Type {
+	case "checkbox":
+		field.Checked = el.Checked()
+		field.Value = ""
+	case "select":
+		if idx := el.SelectedIndex(); idx >= 0 && idx < len(field.Options) {
+			field.selectKey(field.Options[idx].Key)
+		}
+	case "groupselect":
+		if key, err := strconv.Atoi(el.Value()); err == nil {
+			field.Selected = key
+			field.Value = el.Value()
+		}
+	default:
+		field.Value = el.Value()
+	}
+}
+
+// The display text for the value kept on a field
+func displayOf(field *EditField) string {
+	var v interface{} = field.Value
+	switch field.Type {
+	case "checkbox":
+		v = field.Checked
+	case "number":
+		if field.IsFloat {
+			if n, err := strconv.ParseFloat(field.Value, 64); err == nil {
+				v = n
+			}
+		} else if n, err := strconv.Atoi(field.Value); err == nil {
+			v = n
+		}
+	case "date", "datetime-local":
+		if field.Value == "" {
+			return ""
+		}
+		if t, ok := parseDate(field.Value); ok {
+			v = t
+		}
+	}
+	return displayValue(field, reflect.ValueOf(v))
+}
@@ -0,0 +1,107 @@
+package formulate
+
+import (
+	"testing"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+func TestSetDisplayModeKeepsValues(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Rate: 10, Ref: "J-100"}
+	f := newTestForm(job)
+	f.SaveEvent(func(dom.Event) {})
+	f.Render("edit-form", "#form", job)
+	var where *Swapper
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if field.Type == "swapper" {
+				where = field.Swapper
+			}
+		}
+	}
+	where.Select(1)
+
+	fakeField(t, doc, `[name="Name"]`).SetValue("Pump rebuild")
+	fakeField(t, doc, `[name="Rate"]`).SetValue("1234.5")
+	fakeField(t, doc, `[name="Urgent"]`).SetChecked(true)
+	fakeField(t, doc, `[name="Status"]`).SetValue("3")
+
+	f.SetDisplayMode(true)
+	if !f.DisplayMode {
+		t.Fatal("not in display mode")
+	}
+	name := fakeField(t, doc, `[name="Name"]`)
+	if _, ok := name.attrs["readonly"]; !ok || name.Value() != "Pump rebuild" {
+		t.Errorf("Name in display mode = %q %v", name.Value(), name.attrs)
+	}
+	if v := fakeField(t, doc, "#"+f.UID+"-Rate").Value(); v != "1,234.50" {
+		t.Errorf("Rate display = %q", v)
+	}
+	if _, ok := fakeField(t, doc, `[name="Status"]`).attrs["disabled"]; !ok {
+		t.Error("Status can still be changed in display mode")
+	}
+	if doc.QuerySelector(".data-edit-btn") == nil || doc.QuerySelector(".md-save") != nil {
+		t.Error("display mode should have an edit button and no save button")
+	}
+	if _, hidden := fakeField(t, doc, `[name="Where-Office"]`).attrs["aria-hidden"]; hidden {
+		t.Error("the open swapper panel was closed")
+	}
+
+	// nothing binds in display mode
+	got := &testJob{}
+	f.Bind(got)
+	if got.Name != "" {
+		t.Errorf("display mode bound Name = %q", got.Name)
+	}
+
+	f.SetDisplayMode(false)
+	name = fakeField(t, doc, `[name="Name"]`)
+	if _, ok := name.attrs["readonly"]; ok {
+		t.Error("Name is still readonly in edit mode")
+	}
+	if !f.GetField("Ref").Readonly {
+		t.Error("a display field became editable")
+	}
+	if doc.QuerySelector(".data-edit-btn") != nil || doc.QuerySelector(".md-save") == nil {
+		t.Error("edit mode should have a save button and no edit button")
+	}
+	f.Bind(got)
+	if got.Name != "Pump rebuild" || got.Rate != 1234.5 || !got.Urgent || got.Status != 3 {
+		t.Errorf("values were not kept through display mode: %+v", *got)
+	}
+}
+
+func TestSetDisplayModeBeforeRender(t *testing.T) {
+	f := newTestForm(&testJob{Status: 1, Priority: 1})
+	f.SetDisplayMode(true)
+	if !f.DisplayMode || f.GetField("Name").Readonly {
+		t.Error("before render, only DisplayMode should change")
+	}
+}
+
+func TestDisplayModeLocksPanelFields(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f := newLockTestForm()
+	f.Render("edit-form", "#form", nil)
+	if _, ok := fakeField(t, doc, `[name="OnSite"]`).attrs["disabled"]; ok {
+		t.Fatal("OnSite is disabled before display mode")
+	}
+
+	f.SetDisplayMode(true)
+	for _, model := range []string{"Site", "Directions", "Region", "OnSite", "Access"} {
+		attr := lockAttrs[model]
+		for _, el := range doc.QuerySelectorAll(`[name="` + model + `"]`) {
+			if _, ok := el.(*fakeNode).attrs[attr]; !ok {
+				t.Errorf("the panel field %s is not %s in display mode", model, attr)
+			}
+		}
+	}
+	for _, model := range []string{"Visits", "Visited"} {
+		if el := fakeField(t, doc, "#"+f.UID+"-"+model); el.attrs["type"] != "text" {
+			t.Errorf("the panel field %s is not shown as text: %v", model, el.attrs)
+		} else if _, ok := el.attrs["readonly"]; !ok {
+			t.Errorf("the panel field %s is not readonly in display mode", model)
+		}
+	}
+}
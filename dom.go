@@ -0,0 +1,137 @@
+package formulate
+
+import (
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// node is the narrow part of the DOM that rendering and binding need,
+// so that they can run against an in-memory fake in tests
+type node interface {
+	QuerySelector(selector string) node
+	QuerySelectorAll(selector string) []node
+	GetAttribute(name string) string
+	SetAttribute(name string, value string)
+	RemoveAttribute(name string)
+	Value() string
+	SetValue(value string)
+	Checked() bool
+	SetChecked(checked bool)
+	SelectedIndex() int
+	SelectionStart() int
+	SetSelectionRange(start int, end int)
+	AddClass(class string)
+	RemoveClass(class string)
+	HasClass(class string) bool
+	SetInnerHTML(html string)
+	SetTextContent(text string)
+	AddEventListener(event string, cb func(dom.Event)) (remove func())
+	// Element is the real DOM element, or nil if there is no browser
+	Element() dom.Element
+}
+
+// The document that forms are rendered into. Tests swap this for a fake
+var document = func() node {
+	return jsNode{dom.Global().Get("document")}
+}
+
+// jsNode is a node in the browser DOM, with either GopherJS or wasm
+type jsNode struct {
+	v dom.Value
+}
+
+func wrapNode(o dom.Value) node {
+	if dom.IsUndefined(o) {
+		return nil
+	}
+	return jsNode{o}
+}
+
+func wrapElement(el dom.Element) node {
+	if el == nil {
+		return nil
+	}
+	return jsNode{el.Underlying()}
+}
+
+func (n jsNode) QuerySelector(selector string) node {
+	return wrapNode(n.v.Call("querySelector", selector))
+}
+
+func (n jsNode) QuerySelectorAll(selector string) []node {
+	list := n.v.Call("querySelectorAll", selector)
+	nodes := []node{}
+	for i := 0; i < list.Length(); i++ {
+		nodes = append(nodes, jsNode{list.Index(i)})
+	}
+	return nodes
+}
+
+func (n jsNode) GetAttribute(name string) string {
+	v := n.v.Call("getAttribute", name)
+	if dom.IsUndefined(v) {
+		return ""
+	}
+	return v.String()
+}
+
+func (n jsNode) SetAttribute(name string, value string) { n.v.Call("setAttribute", name, value) }
+func (n jsNode) RemoveAttribute(name string)            { n.v.Call("removeAttribute", name) }
+func (n jsNode) Value() string                          { return n.v.Get("value").String() }
+func (n jsNode) SetValue(value string)                  { n.v.Set("value", value) }
+func (n jsNode) Checked() bool                          { return n.v.Get("checked").Bool() }
+func (n jsNode) SetChecked(checked bool)                { n.v.Set("checked", checked) }
+func (n jsNode) SelectedIndex() int                     { return n.v.Get("selectedIndex").Int() }
+func (n jsNode) AddClass(class string)                  { n.v.Get("classList").Call("add", class) }
+func (n jsNode) RemoveClass(class string)               { n.v.Get("classList").Call("remove", class) }
+func (n jsNode) HasClass(class string) bool {
+	return n.v.Get("classList").Call("contains", class).Bool()
+}
+
+// The cursor of a text input, or -1 if the node has none
+func (n jsNode) SelectionStart() int {
+	v := n.v.Get("selectionStart")
+	if dom.IsUndefined(v) {
+		return -1
+	}
+	return v.Int()
+}
+
+func (n jsNode) SetSelectionRange(start int, end int) {
+	n.v.Call("setSelectionRange", start, end)
+}
+
+func (n jsNode) SetInnerHTML(html string)   { n.v.Set("innerHTML", html) }
+func (n jsNode) SetTextContent(text string) { n.v.Set("textContent", text) }
+func (n jsNode) Element() dom.Element       { return dom.WrapElement(n.v) }
+
+func (n jsNode) AddEventListener(event string, cb func(dom.Event)) func() {
+	el := dom.WrapElement(n.v)
+	fn := el.AddEventListener(event, false, cb)
+	return func() {
+		el.RemoveEventListener(event, false, fn)
+	}
+}
+
+// Add or remove a set of space separated classes on a node
+func setNodeClass(n node, classes string, on bool) {
+	if n == nil {
+		return
+	}
+	for _, c := range strings.Fields(classes) {
+		if on {
+			n.AddClass(c)
+		} else {
+			n.RemoveClass(c)
+		}
+	}
+}
+
+// The real element behind a node, if there is one
+func elementOf(n node) dom.Element {
+	if n == nil {
+		return nil
+	}
+	return n.Element()
+}
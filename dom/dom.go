@@ -0,0 +1,80 @@
+//go:build !(js && wasm)
+
+// Package dom is the part of the browser DOM that formulate uses.
+//
+// With GopherJS the types are aliases of honnef.co/go/js/dom, so code that
+// already uses that package passes its values straight in. With
+// GOOS=js GOARCH=wasm they are implemented on syscall/js instead.
+package dom
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/dom"
+)
+
+type (
+	// Value is a raw JavaScript value
+	Value = *js.Object
+
+	EventTarget       = dom.EventTarget
+	Event             = dom.Event
+	KeyboardEvent     = dom.KeyboardEvent
+	Window            = dom.Window
+	Document          = dom.Document
+	HTMLDocument      = dom.HTMLDocument
+	Node              = dom.Node
+	Element           = dom.Element
+	HTMLElement       = dom.HTMLElement
+	HTMLDivElement    = dom.HTMLDivElement
+	HTMLInputElement  = dom.HTMLInputElement
+	HTMLImageElement  = dom.HTMLImageElement
+	HTMLCanvasElement = dom.HTMLCanvasElement
+	TokenList         = dom.TokenList
+	ClientRect        = dom.ClientRect
+)
+
+// GetWindow returns the browser window
+func GetWindow() Window {
+	return dom.GetWindow()
+}
+
+// WrapElement wraps a raw element, returning nil for null or undefined
+func WrapElement(o Value) Element {
+	if IsUndefined(o) {
+		return nil
+	}
+	return dom.WrapElement(o)
+}
+
+// WrapEvent wraps a raw event
+func WrapEvent(o Value) Event {
+	return dom.WrapEvent(o)
+}
+
+// Global returns the JavaScript global object
+func Global() Value {
+	return js.Global
+}
+
+// HasDOM reports whether there is a browser document, rather than
+// the standard Go toolchain on a server
+func HasDOM() bool {
+	return js.Global != nil && !IsUndefined(js.Global.Get("document"))
+}
+
+// IsUndefined reports whether the value is undefined or null
+func IsUndefined(v Value) bool {
+	return v == nil || v == js.Undefined
+}
+
+// Equal reports whether two values are the same JavaScript object
+func Equal(a, b Value) bool {
+	return a == b
+}
+
+// Func turns fn into a value that can be set as a JavaScript callback,
+// such as the onload of a FileReader. Call release once the callback is done
+// with. GopherJS collects the callback itself, so release does nothing
+func Func(fn func(Value)) (callback interface{}, release func()) {
+	return fn, func() {}
+}
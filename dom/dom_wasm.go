@@ -0,0 +1,447 @@
+//go:build js && wasm
+
+// Package dom is the part of the browser DOM that formulate uses.
+//
+// With GopherJS the types are aliases of honnef.co/go/js/dom, so code that
+// already uses that package passes its values straight in. With
+// GOOS=js GOARCH=wasm they are implemented on syscall/js instead.
+package dom
+
+import (
+	"strings"
+	"syscall/js"
+)
+
+// Value is a raw JavaScript value
+type Value = js.Value
+
+// GetWindow returns the browser window
+func GetWindow() Window {
+	return &window{js.Global().Get("window")}
+}
+
+// Global returns the JavaScript global object
+func Global() Value {
+	return js.Global()
+}
+
+// HasDOM reports whether there is a browser document, rather than
+// a wasm runtime such as node without one
+func HasDOM() bool {
+	return !IsUndefined(js.Global().Get("document"))
+}
+
+// IsUndefined reports whether the value is undefined or null
+func IsUndefined(v Value) bool {
+	return v.IsUndefined() || v.IsNull()
+}
+
+// Equal reports whether two values are the same JavaScript object
+func Equal(a, b Value) bool {
+	return a.Equal(b)
+}
+
+// Func turns fn into a value that can be set as a JavaScript callback,
+// such as the onload of a FileReader. Call release once the callback is done
+// with, as it lives as long as the page until then
+func Func(fn func(Value)) (callback interface{}, release func()) {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		arg := js.Undefined()
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		fn(arg)
+		return nil
+	})
+	return f, f.Release
+}
+
+type EventTarget interface {
+	AddEventListener(typ string, useCapture bool, listener func(Event)) js.Func
+	RemoveEventListener(typ string, useCapture bool, listener js.Func)
+}
+
+func addEventListener(v js.Value, typ string, useCapture bool, listener func(Event)) js.Func {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		listener(WrapEvent(args[0]))
+		return nil
+	})
+	v.Call("addEventListener", typ, fn, useCapture)
+	return fn
+}
+
+func removeEventListener(v js.Value, typ string, useCapture bool, listener js.Func) {
+	v.Call("removeEventListener", typ, listener, useCapture)
+	listener.Release()
+}
+
+type Window interface {
+	EventTarget
+	Document() Document
+	InnerWidth() int
+	InnerHeight() int
+	ScrollX() int
+	ScrollY() int
+	Scroll(x, y int)
+	ScrollTo(x, y int)
+	Print()
+}
+
+type window struct {
+	js.Value
+}
+
+func (w *window) AddEventListener(typ string, useCapture bool, listener func(Event)) js.Func {
+	return addEventListener(w.Value, typ, useCapture, listener)
+}
+
+func (w *window) RemoveEventListener(typ string, useCapture bool, listener js.Func) {
+	removeEventListener(w.Value, typ, useCapture, listener)
+}
+
+func (w *window) Document() Document {
+	return &document{&BasicNode{w.Get("document")}}
+}
+
+func (w *window) InnerWidth() int   { return w.Get("innerWidth").Int() }
+func (w *window) InnerHeight() int  { return w.Get("innerHeight").Int() }
+func (w *window) ScrollX() int      { return w.Get("scrollX").Int() }
+func (w *window) ScrollY() int      { return w.Get("scrollY").Int() }
+func (w *window) Scroll(x, y int)   { w.Call("scroll", x, y) }
+func (w *window) ScrollTo(x, y int) { w.Call("scrollTo", x, y) }
+func (w *window) Print()            { w.Call("print") }
+
+type Node interface {
+	EventTarget
+	Underlying() js.Value
+	ParentNode() Node
+	ParentElement() Element
+	FirstChild() Node
+	NextSibling() Node
+	TextContent() string
+	SetTextContent(string)
+	AppendChild(newchild Node)
+	InsertBefore(which Node, before Node)
+	RemoveChild(oldChild Node)
+	Contains(Node) bool
+}
+
+// BasicNode implements Node. The raw value is embedded, as with GopherJS,
+// so that Get, Set and Call can be used on any node
+type BasicNode struct {
+	js.Value
+}
+
+func wrapNode(o js.Value) Node {
+	if IsUndefined(o) {
+		return nil
+	}
+	if o.Get("nodeType").Int() == 1 {
+		return WrapElement(o)
+	}
+	return &BasicNode{o}
+}
+
+func (n *BasicNode) AddEventListener(typ string, useCapture bool, listener func(Event)) js.Func {
+	return addEventListener(n.Value, typ, useCapture, listener)
+}
+
+func (n *BasicNode) RemoveEventListener(typ string, useCapture bool, listener js.Func) {
+	removeEventListener(n.Value, typ, useCapture, listener)
+}
+
+func (n *BasicNode) Underlying() js.Value      { return n.Value }
+func (n *BasicNode) ParentNode() Node          { return wrapNode(n.Get("parentNode")) }
+func (n *BasicNode) ParentElement() Element    { return WrapElement(n.Get("parentElement")) }
+func (n *BasicNode) FirstChild() Node          { return wrapNode(n.Get("firstChild")) }
+func (n *BasicNode) NextSibling() Node         { return wrapNode(n.Get("nextSibling")) }
+func (n *BasicNode) TextContent() string       { return n.Get("textContent").String() }
+func (n *BasicNode) SetTextContent(s string)   { n.Set("textContent", s) }
+func (n *BasicNode) AppendChild(newchild Node) { n.Call("appendChild", newchild.Underlying()) }
+func (n *BasicNode) RemoveChild(oldChild Node) { n.Call("removeChild", oldChild.Underlying()) }
+
+func (n *BasicNode) InsertBefore(which Node, before Node) {
+	ref := js.Null()
+	if before != nil {
+		ref = before.Underlying()
+	}
+	n.Call("insertBefore", which.Underlying(), ref)
+}
+
+func (n *BasicNode) Contains(other Node) bool {
+	if other == nil {
+		return false
+	}
+	return n.Call("contains", other.Underlying()).Bool()
+}
+
+type Document interface {
+	Node
+	CreateElement(name string) Element
+	GetElementByID(id string) Element
+	QuerySelector(sel string) Element
+	QuerySelectorAll(sel string) []Element
+}
+
+type HTMLDocument interface {
+	Document
+	ActiveElement() HTMLElement
+	Body() HTMLElement
+}
+
+type document struct {
+	*BasicNode
+}
+
+func (d *document) CreateElement(name string) Element {
+	return WrapElement(d.Call("createElement", name))
+}
+
+func (d *document) GetElementByID(id string) Element {
+	return WrapElement(d.Call("getElementById", id))
+}
+
+func (d *document) QuerySelector(sel string) Element {
+	return WrapElement(d.Call("querySelector", sel))
+}
+
+func (d *document) QuerySelectorAll(sel string) []Element {
+	return elements(d.Call("querySelectorAll", sel))
+}
+
+func (d *document) ActiveElement() HTMLElement {
+	el, _ := WrapElement(d.Get("activeElement")).(HTMLElement)
+	return el
+}
+
+func (d *document) Body() HTMLElement {
+	el, _ := WrapElement(d.Get("body")).(HTMLElement)
+	return el
+}
+
+type Element interface {
+	Node
+	Class() *TokenList
+	Closest(string) Element
+	ID() string
+	SetID(string)
+	TagName() string
+	GetAttribute(string) string
+	SetAttribute(name string, value string)
+	RemoveAttribute(string)
+	HasAttribute(string) bool
+	GetBoundingClientRect() ClientRect
+	Matches(string) bool
+	QuerySelector(string) Element
+	QuerySelectorAll(string) []Element
+	InnerHTML() string
+	SetInnerHTML(string)
+}
+
+// ClientRect is a snapshot of an element's position, as from getBoundingClientRect
+type ClientRect struct {
+	Height float64
+	Width  float64
+	Left   float64
+	Right  float64
+	Top    float64
+	Bottom float64
+}
+
+// WrapElement wraps a raw element, returning nil for null or undefined
+func WrapElement(o Value) Element {
+	if IsUndefined(o) {
+		return nil
+	}
+	el := &BasicHTMLElement{&BasicElement{&BasicNode{o}}}
+	switch strings.ToLower(o.Get("tagName").String()) {
+	case "div":
+		return &HTMLDivElement{el}
+	case "input":
+		return &HTMLInputElement{el}
+	case "img":
+		return &HTMLImageElement{el}
+	case "canvas":
+		return &HTMLCanvasElement{el}
+	}
+	return el
+}
+
+func elements(list js.Value) []Element {
+	els := []Element{}
+	for i := 0; i < list.Length(); i++ {
+		els = append(els, WrapElement(list.Index(i)))
+	}
+	return els
+}
+
+type BasicElement struct {
+	*BasicNode
+}
+
+func (e *BasicElement) Class() *TokenList               { return &TokenList{e.Get("classList")} }
+func (e *BasicElement) Closest(sel string) Element      { return WrapElement(e.Call("closest", sel)) }
+func (e *BasicElement) ID() string                      { return e.Get("id").String() }
+func (e *BasicElement) SetID(id string)                 { e.Set("id", id) }
+func (e *BasicElement) TagName() string                 { return e.Get("tagName").String() }
+func (e *BasicElement) SetAttribute(name, value string) { e.Call("setAttribute", name, value) }
+func (e *BasicElement) RemoveAttribute(name string)     { e.Call("removeAttribute", name) }
+func (e *BasicElement) HasAttribute(name string) bool   { return e.Call("hasAttribute", name).Bool() }
+func (e *BasicElement) Matches(sel string) bool         { return e.Call("matches", sel).Bool() }
+func (e *BasicElement) QuerySelector(sel string) Element {
+	return WrapElement(e.Call("querySelector", sel))
+}
+func (e *BasicElement) InnerHTML() string     { return e.Get("innerHTML").String() }
+func (e *BasicElement) SetInnerHTML(s string) { e.Set("innerHTML", s) }
+
+func (e *BasicElement) GetAttribute(name string) string {
+	v := e.Call("getAttribute", name)
+	if IsUndefined(v) {
+		return ""
+	}
+	return v.String()
+}
+
+func (e *BasicElement) QuerySelectorAll(sel string) []Element {
+	return elements(e.Call("querySelectorAll", sel))
+}
+
+func (e *BasicElement) GetBoundingClientRect() ClientRect {
+	r := e.Call("getBoundingClientRect")
+	return ClientRect{
+		Height: r.Get("height").Float(),
+		Width:  r.Get("width").Float(),
+		Left:   r.Get("left").Float(),
+		Right:  r.Get("right").Float(),
+		Top:    r.Get("top").Float(),
+		Bottom: r.Get("bottom").Float(),
+	}
+}
+
+type HTMLElement interface {
+	Element
+	OffsetHeight() float64
+	OffsetLeft() float64
+	OffsetParent() HTMLElement
+	OffsetTop() float64
+	OffsetWidth() float64
+	Style() *CSSStyleDeclaration
+	Blur()
+	Click()
+	Focus()
+}
+
+type BasicHTMLElement struct {
+	*BasicElement
+}
+
+func (e *BasicHTMLElement) OffsetHeight() float64       { return e.Get("offsetHeight").Float() }
+func (e *BasicHTMLElement) OffsetLeft() float64         { return e.Get("offsetLeft").Float() }
+func (e *BasicHTMLElement) OffsetTop() float64          { return e.Get("offsetTop").Float() }
+func (e *BasicHTMLElement) OffsetWidth() float64        { return e.Get("offsetWidth").Float() }
+func (e *BasicHTMLElement) Style() *CSSStyleDeclaration { return &CSSStyleDeclaration{e.Get("style")} }
+func (e *BasicHTMLElement) Blur()                       { e.Call("blur") }
+func (e *BasicHTMLElement) Click()                      { e.Call("click") }
+func (e *BasicHTMLElement) Focus()                      { e.Call("focus") }
+
+func (e *BasicHTMLElement) OffsetParent() HTMLElement {
+	el, _ := WrapElement(e.Get("offsetParent")).(HTMLElement)
+	return el
+}
+
+type HTMLDivElement struct{ *BasicHTMLElement }
+type HTMLInputElement struct{ *BasicHTMLElement }
+type HTMLImageElement struct{ *BasicHTMLElement }
+type HTMLCanvasElement struct{ *BasicHTMLElement }
+
+// Select selects the text in the input
+func (e *HTMLInputElement) Select() {
+	e.Call("select")
+}
+
+// GetContext returns the canvas's drawing context, such as "2d"
+func (e *HTMLCanvasElement) GetContext(param string) js.Value {
+	return e.Call("getContext", param)
+}
+
+type CSSStyleDeclaration struct {
+	js.Value
+}
+
+func (css *CSSStyleDeclaration) SetProperty(name, value, priority string) {
+	css.Call("setProperty", name, value, priority)
+}
+
+func (css *CSSStyleDeclaration) RemoveProperty(name string) {
+	css.Call("removeProperty", name)
+}
+
+func (css *CSSStyleDeclaration) GetPropertyValue(name string) string {
+	return css.Call("getPropertyValue", name).String()
+}
+
+type TokenList struct {
+	v js.Value
+}
+
+func (tl *TokenList) Item(idx int) string        { return tl.v.Call("item", idx).String() }
+func (tl *TokenList) Contains(token string) bool { return tl.v.Call("contains", token).Bool() }
+func (tl *TokenList) Add(token string)           { tl.v.Call("add", token) }
+func (tl *TokenList) Remove(token string)        { tl.v.Call("remove", token) }
+func (tl *TokenList) Toggle(token string)        { tl.v.Call("toggle", token) }
+func (tl *TokenList) String() string             { return tl.v.Call("toString").String() }
+func (tl *TokenList) SetString(s string)         { tl.v.Set("value", s) }
+
+type Event interface {
+	CurrentTarget() Element
+	DefaultPrevented() bool
+	Target() Element
+	Type() string
+	PreventDefault()
+	StopImmediatePropagation()
+	StopPropagation()
+	Underlying() js.Value
+}
+
+// WrapEvent wraps a raw event
+func WrapEvent(o Value) Event {
+	ev := &BasicEvent{o}
+	if c := js.Global().Get("KeyboardEvent"); !IsUndefined(c) && o.InstanceOf(c) {
+		return &KeyboardEvent{
+			BasicEvent: ev,
+			AltKey:     o.Get("altKey").Bool(),
+			CtrlKey:    o.Get("ctrlKey").Bool(),
+			Key:        o.Get("key").String(),
+			KeyCode:    o.Get("keyCode").Int(),
+			MetaKey:    o.Get("metaKey").Bool(),
+			RepeatKey:  o.Get("repeat").Bool(),
+			ShiftKey:   o.Get("shiftKey").Bool(),
+		}
+	}
+	return ev
+}
+
+type BasicEvent struct {
+	js.Value
+}
+
+func (ev *BasicEvent) CurrentTarget() Element    { return WrapElement(ev.Get("currentTarget")) }
+func (ev *BasicEvent) DefaultPrevented() bool    { return ev.Get("defaultPrevented").Bool() }
+func (ev *BasicEvent) Target() Element           { return WrapElement(ev.Get("target")) }
+func (ev *BasicEvent) Type() string              { return ev.Get("type").String() }
+func (ev *BasicEvent) PreventDefault()           { ev.Call("preventDefault") }
+func (ev *BasicEvent) StopImmediatePropagation() { ev.Call("stopImmediatePropagation") }
+func (ev *BasicEvent) StopPropagation()          { ev.Call("stopPropagation") }
+func (ev *BasicEvent) Underlying() js.Value      { return ev.Value }
+
+// KeyboardEvent has the same fields as with GopherJS, read when the event is wrapped
+type KeyboardEvent struct {
+	*BasicEvent
+	AltKey    bool
+	CtrlKey   bool
+	Key       string
+	KeyCode   int
+	MetaKey   bool
+	RepeatKey bool
+	ShiftKey  bool
+}
@@ -9,7 +9,7 @@ import (
 	"time"
 	"unsafe"
 
-	"honnef.co/go/js/dom"
+	"github.com/steveoc64/formulate/dom"
 )
 
 // SelectOption - datatype for things that can appear in a select list
@@ -36,6 +36,7 @@ type EditField struct {
 	Type        string
 	Model       string
 	Value       string
+	Display     string
 	Checked     bool
 	Focusme     bool
 	Readonly    bool
@@ -55,6 +56,23 @@ type EditField struct {
 	Preview     bool
 	Thumbnail   bool
 	Autofocus   bool
+	Accept      string
+	MaxFiles    int
+	MaxSize     int
+	Files       []FileField
+	PhotoOpts   *PhotoOptions
+	Rules       *Rules         // checked by Validate
+	Enum        []string       // the values a select stands for, when it binds strings rather than keys
+	ListOf      string         // for a textarea holding a list, the JSON type of the items, one per line
+	Placeholder string         // an example value, shown while the field is empty
+	Help        string         // shown under the field
+	Tooltip     string         // shown on an icon beside the label
+	Mask        string         // the pattern of a masked text input, see AddMasked
+	MaskRaw     bool           // bind a masked input without the literals of the mask
+	Spans       map[string]int // the span at each breakpoint that differs from Span
+	locked      bool           // readonly only because of display mode or the policy
+	hidden      bool           // hidden by the policy
+	reading     int            // files that are being read, which count towards MaxFiles
 }
 
 func (e *EditField) GetSelected() string {
@@ -82,28 +100,54 @@ type FileField struct {
 
 type EditRow struct {
 	Span   int
+	Spans  map[string]int // the span at each breakpoint that differs from Span
 	Fields []*EditField
 }
 
 type EditForm struct {
-	Title       string
-	Icon        string
-	ID          int
-	Rows        []*EditRow
-	CancelCB    func(dom.Event)
-	DeleteCB    func(dom.Event)
-	SaveCB      func(dom.Event)
-	PrintCB     func(dom.Event)
-	ChangeCB    func(dom.Event)
-	AttachCB    func()
-	IsRendered  bool
-	DisplayMode bool
+	Title          string
+	Icon           string
+	ID             int
+	Rows           []*EditRow
+	CancelCB       func(dom.Event)
+	DeleteCB       func(dom.Event)
+	SaveCB         func(dom.Event)
+	PrintCB        func(dom.Event)
+	ChangeCB       func(dom.Event)
+	AttachCB       func()
+	ReadProgressCB func(string, string, int, int)
+	IsRendered     bool
+	DisplayMode    bool
+	Live           bool // bind each field into the data as it is edited
+	Policy         *Policy
+	Version        string // the version or ETag of the data that is being edited
+	VersionModel   string // the model that holds the version in the data
+	Shortcuts      []*Shortcut
+	UID            string
+	Root           dom.Element
+	root           node
+	Theme          Theme
+	listeners      listeners
+	rerender       func()
+	repaint        func()
+	data           interface{} // the data that the form was rendered with
+	dataType       reflect.Type
+	original       map[*EditField]fieldState // the values the form was rendered with
+	conflict       *conflictState
+	breakpoint     string
+	watchers       []fieldWatcher
+	watched        map[*EditField]interface{} // the values that subscriptions were last told of
+	lastFocus      dom.HTMLElement
+	events         map[string]string // callback names, by event
 }
 
 type Swapper struct {
 	Name     string
 	Selected int
 	Panels   []*Panel
+	Root     dom.Element
+	root     node
+	theme    Theme
 }
 
 func (s *Swapper) AddPanel(panelName string) *Panel {
@@ -124,39 +168,43 @@ func (s *Swapper) Current() *Panel {
 }
 
 func (s *Swapper) Select(idx int) {
-	w := dom.GetWindow()
-	doc := w.Document()
-
 	// Show or unshow all panels by name
 	for i, p := range s.Panels {
-		// print("lookup", fmt.Sprintf("[name=%s-%s]", s.Name, p.Name))
-		el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", s.Name, p.Name)).(*dom.HTMLDivElement)
+		// print("lookup", fmt.Sprintf(`[name="%s-%s"]`, s.Name, p.Name))
+		el := s.query(fmt.Sprintf(`[name="%s-%s"]`, s.Name, p.Name))
 		if el != nil {
-			cl := el.Class()
 			if i == idx {
 				s.Selected = i
-				cl.Add("swapper-show")
+				setNodeClass(el, s.currentTheme().Class("swapper-show"), true)
+				el.RemoveAttribute("aria-hidden")
 			} else {
-				cl.Remove("swapper-show")
+				setNodeClass(el, s.currentTheme().Class("swapper-show"), false)
+				el.SetAttribute("aria-hidden", "true")
 			}
 		}
 	}
 }
 
-func (s *Swapper) SelectByName(name string) {
-	w := dom.GetWindow()
-	doc := w.Document()
+// The theme of the form that the swapper was rendered in
+func (s *Swapper) currentTheme() Theme {
+	if s.theme != nil {
+		return s.theme
+	}
+	return theme
+}
 
+func (s *Swapper) SelectByName(name string) {
 	// Show or unshow all panels by name
 	for i, p := range s.Panels {
-		el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", s.Name, p.Name)).(*dom.HTMLDivElement)
+		el := s.query(fmt.Sprintf(`[name="%s-%s"]`, s.Name, p.Name))
 		if el != nil {
-			cl := el.Class()
 			if p.Name == name {
 				s.Selected = i
-				cl.Add("swapper-show")
+				setNodeClass(el, s.currentTheme().Class("swapper-show"), true)
+				el.RemoveAttribute("aria-hidden")
 			} else {
-				cl.Remove("swapper-show")
+				setNodeClass(el, s.currentTheme().Class("swapper-show"), false)
+				el.SetAttribute("aria-hidden", "true")
 			}
 		}
 	}
@@ -167,6 +215,10 @@ type Panel struct {
 	Div          *dom.HTMLDivElement
 	Rows         []*EditRow
 	BindWithForm bool
+	Root         dom.Element
+	root         node
+	policy       *Policy
+	form         *EditForm
 }
 
 func (p *Panel) Row(s int) *EditRow {
@@ -181,110 +233,21 @@ func (p *Panel) AddRow(s int) *EditRow {
 	return p.Row(s)
 }
 
+// Paint loads the fields of the panel from the data, and writes those that have changed into the form
 func (p *Panel) Paint(data interface{}) {
-
-	w := dom.GetWindow()
-	doc := w.Document()
-	// print("paint with data", data)
-
-	if data != nil {
-		// Make sure the type of v is a pointer to a struct.
-		doit := true
-		ptrType := reflect.TypeOf(data)
-		if ptrType.Kind() != reflect.Ptr {
-			doit = false
-		}
-		typ := ptrType.Elem()
-		if typ.Kind() != reflect.Struct {
-			doit = false
-		}
-		ptrVal := reflect.ValueOf(data)
-		if ptrVal.IsNil() {
-			doit = false
-		}
-
-		if doit {
-			for _, r := range p.Rows {
-				for _, f := range r.Fields {
-					// print("render swapper field", f.Model)
-					dataField := reflect.Indirect(ptrVal).FieldByName(f.Model)
-					boolValue := false
-					// print("Field Type", f.Type)
-					switch dataField.Kind() {
-					case reflect.Float64:
-						f.Value = fmt.Sprintf("%.2f", dataField.Float())
-					case reflect.Int:
-						f.Value = fmt.Sprintf("%d", dataField.Int())
-					case reflect.Bool:
-						boolValue = dataField.Bool()
-					case reflect.Ptr:
-						// print("odd case of a swapper field being a ptr", f.Model, f.Type)
-						switch f.Type {
-						case "date":
-							f.Value = ""
-							ptr := unsafe.Pointer(dataField.Pointer())
-							if ptr != nil {
-								t := *(*time.Time)(ptr)
-								f.Value = t.Format(rfc3339DateLayout)
-							}
-						case "number":
-							f.Value = ""
-							ptr := unsafe.Pointer(dataField.Pointer())
-							if ptr != nil {
-								if f.IsFloat {
-									v := *(*float64)(ptr)
-									f.Value = fmt.Sprintf("%f", v)
-								} else {
-									v := *(*int)(ptr)
-									f.Value = fmt.Sprintf("%d", v)
-
-								}
-							}
-						}
-					case reflect.String:
-						f.Value = dataField.String()
-					default:
-						f.Value = dataField.String()
-					}
-					// print("Field", f.Type, f.Model, f.Value)
-					switch f.Type {
-					case "text", "number":
-						// print("lookup", fmt.Sprintf("[name=%s-%s]", p.Name, f.Model))
-						el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", p.Name, f.Model)).(*dom.HTMLInputElement)
-						el.Value = f.Value
-					case "textarea":
-						el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", p.Name, f.Model)).(*dom.HTMLTextAreaElement)
-						el.Value = f.Value
-					case "select":
-						if f.Readonly {
-							el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", p.Name, f.Model)).(*dom.HTMLInputElement)
-							// v, _ := strconv.Atoi(f.Value)
-							print("painting panel selector with ", f.Value)
-							// el.Value = fmt.Sprintf("%s", f.Options[v].Display)
-							el.Value = f.GetSelected()
-						} else {
-							el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", p.Name, f.Model)).(*dom.HTMLSelectElement)
-							el.Value = f.Value
-						}
-					case "checkbox":
-						el := doc.QuerySelector(fmt.Sprintf("[name=%s-%s]", p.Name, f.Model)).(*dom.HTMLInputElement)
-						el.Checked = boolValue
-					}
-				}
-			}
-		}
+	if p.form == nil {
+		print("ERROR: Paint called on panel", p.Name, "before the form is rendered")
+		return
 	}
-
+	p.form.paintFields(p.fields(), data)
 }
 
-// Get the editfield of the given name
+// Get the editfield of the given name, which may be on a swapper panel
 func (f *EditForm) GetField(name string) *EditField {
 
-	for _, row := range f.Rows {
-		for _, field := range row.Fields {
-			if field.Model == name {
-				return field
-			}
+	for _, field := range f.allFields() {
+		if field.Model == name {
+			return field
 		}
 	}
 	return nil
@@ -389,14 +352,24 @@ func (f *EditForm) AttachEvent(c func()) *EditForm {
 	return f
 }
 
-// Associate a print event with the editform
+// Associate a read progress callback with the editform, called with the model,
+// filename, bytes loaded and bytes total as files are read from the user's disk into
+// a files field. This is not upload progress, as the files are sent with the form
+// when it is saved. Small files are read at once, and report only when they are done
+func (f *EditForm) ReadProgressEvent(c func(string, string, int, int)) *EditForm {
+	if f.IsRendered {
+		print("ERROR: ReadProgressEvent() called after render")
+	}
+	f.ReadProgressCB = c
+	return f
+}
+
+// Associate a print event with the editform. Print renders a document for printing
 func (f *EditForm) PrintEvent(c func(dom.Event)) *EditForm {
-	if dom.GetWindow().InnerWidth() > 740 {
-		if f.IsRendered {
-			print("ERROR: PrintEvent() called after render")
-		}
-		f.PrintCB = c
+	if f.IsRendered {
+		print("ERROR: PrintEvent() called after render")
 	}
+	f.PrintCB = c
 	return f
 }
 
@@ -488,7 +461,8 @@ func (r *EditRow) AddImage(span int, label string, model string) *EditRow {
 }
 
 // Add a Photo field. The Model must be of type FileField
-func (r *EditRow) AddPhoto(span int, label string, model string) *EditRow {
+// Pass PhotoOptions to resize and compress the photo on the client before it is previewed
+func (r *EditRow) AddPhoto(span int, label string, model string, opts ...PhotoOptions) *EditRow {
 	f := &EditField{
 		Span:        span,
 		Label:       label,
@@ -499,6 +473,43 @@ func (r *EditRow) AddPhoto(span int, label string, model string) *EditRow {
 		Model:       model,
 		Readonly:    false,
 	}
+	if len(opts) > 0 {
+		f.PhotoOpts = &opts[0]
+	}
+	r.Fields = append(r.Fields, f)
+	return r
+}
+
+// Add a multi file upload field. The Model must be of type []FileField
+// accept is a comma separated list of MIME types or extensions, as per the
+// HTML accept attribute. maxFiles and maxSize (in bytes) of 0 mean no limit
+func (r *EditRow) AddFiles(span int, label string, model string, accept string, maxFiles int, maxSize int) *EditRow {
+	f := &EditField{
+		Span:     span,
+		Label:    label,
+		Type:     "files",
+		Focusme:  false,
+		Model:    model,
+		Readonly: false,
+		Accept:   accept,
+		MaxFiles: maxFiles,
+		MaxSize:  maxSize,
+	}
+	r.Fields = append(r.Fields, f)
+	return r
+}
+
+// Add a Signature capture field. The Model must be a string, which gets a PNG
+// data URL, or a FileField
+func (r *EditRow) AddSignature(span int, label string, model string) *EditRow {
+	f := &EditField{
+		Span:     span,
+		Label:    label,
+		Type:     "signature",
+		Focusme:  false,
+		Model:    model,
+		Readonly: false,
+	}
 	r.Fields = append(r.Fields, f)
 	return r
 }
@@ -828,13 +839,48 @@ func (r *EditRow) AddCustom(span int, label string, model string, class string)
 // Render the form
 func (f *EditForm) Render(template string, selector string, data interface{}) {
 
-	w := dom.GetWindow()
-	doc := w.Document()
+	// drop the listeners from any previous render, so they dont stack up
+	f.listeners.removeAll()
+	f.rerender = func() {
+		f.Render(template, selector, data)
+	}
+	f.repaint = func() {
+		f.listeners.removeAll()
+		renderTemplateNode(template, f.root, f)
+		f.scope()
+		f.decorate(data)
+	}
 	f.IsRendered = true
+	f.attach(selector)
+	if f.root == nil {
+		print("Could not find selector", selector)
+		return
+	}
+	f.load(data)
+	f.remember(data)
+	renderTemplateNode(template, f.root, f)
+	f.scope()
+	f.decorate(data)
+	scrollToTop(f.Root)
+}
+
+// Load the field values from the data, ready for the template
+func (f *EditForm) load(data interface{}) {
 
 	// Tricky part here - if data is passed in, then
 	// load the field values from the data
 
+	f.breakpoint = CurrentBreakpoint()
+	f.loadHints(data)
+	defer f.maskValues()
+	// whatever the data, including swapper panel fields and a form with no data
+	for _, field := range f.allFields() {
+		f.lockField(field)
+	}
+	if m, ok := dataMap(data); ok {
+		f.loadMap(m)
+		return
+	}
 	if data != nil {
 		// Make sure the type of v is a pointer to a struct.
 		doit := true
@@ -854,9 +900,6 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 		if doit {
 			for _, row := range f.Rows {
 				for _, field := range row.Fields {
-					if f.DisplayMode {
-						field.Readonly = true
-					}
 					if field.Model != "" {
 						switch field.Type {
 						case "div":
@@ -864,47 +907,7 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 						case "photo":
 							// print("Render the contents of the photo field after the DOM has been loaded")
 						default:
-							dataField := reflect.Indirect(ptrVal).FieldByName(field.Model)
-							switch dataField.Kind() {
-							case reflect.Float64:
-								// print(field.Model + " of type " + dataField.Kind().String())
-								field.Value = fmt.Sprintf("%.2f", dataField.Float())
-							case reflect.Int:
-								// print(field.Model + " of type " + dataField.Kind().String())
-								field.Value = fmt.Sprintf("%d", dataField.Int())
-							case reflect.Ptr:
-								// print(field.Model + " of type " + dataField.Kind().String())
-								switch field.Type {
-								case "date":
-									field.Value = ""
-									ptr := unsafe.Pointer(dataField.Pointer())
-									if ptr != nil {
-										t := *(*time.Time)(ptr)
-										field.Value = t.Format(rfc3339DateLayout)
-									}
-								case "number":
-									field.Value = ""
-									ptr := unsafe.Pointer(dataField.Pointer())
-									if ptr != nil {
-										if field.IsFloat {
-											v := *(*float64)(ptr)
-											field.Value = fmt.Sprintf("%f", v)
-										} else {
-											v := *(*int)(ptr)
-											field.Value = fmt.Sprintf("%d", v)
-										}
-									}
-								default:
-									field.Value = dataField.String()
-								}
-							case reflect.String:
-								field.Value = dataField.String()
-							case reflect.Bool:
-								field.Checked = dataField.Bool()
-							default:
-								// print(field.Model + " of type " + dataField.Kind().String())
-								field.Value = dataField.String()
-							}
+							f.loadValue(field, modelField(ptrVal, field.Model, false))
 						}
 					} else { // field has no model - it could be a swapper
 						switch field.Type {
@@ -913,9 +916,6 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 							for _, p := range field.Swapper.Panels {
 								for _, r := range p.Rows {
 									for _, sf := range r.Fields {
-										if f.DisplayMode {
-											sf.Readonly = true
-										}
 										// print("render swapper field", f.Model)
 										dataField := reflect.Indirect(ptrVal).FieldByName(sf.Model)
 										switch dataField.Kind() {
@@ -949,9 +949,14 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 											}
 										case reflect.String:
 											sf.Value = dataField.String()
+										case reflect.Bool:
+											sf.Checked = dataField.Bool()
 										default:
 											sf.Value = dataField.String()
 										}
+										if f.DisplayMode {
+											sf.Display = displayValue(sf, dataField)
+										}
 									}
 								}
 							}
@@ -962,17 +967,120 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 		}
 
 	}
+}
 
-	renderTemplate(template, selector, f)
+// Load a value from the data into a field, formatted the way the template shows it
+func (f *EditForm) loadValue(field *EditField, dataField reflect.Value) {
+	switch field.Type {
+	case "files":
+		field.Files = getFiles(dataField)
+		return
+	case "signature":
+		field.Value = getSignature(dataField)
+		return
+	}
+	switch dataField.Kind() {
+	case reflect.Invalid:
+		// not in the data, or set to nil
+		field.Value = ""
+	case reflect.Float32, reflect.Float64:
+		// print(field.Model + " of type " + dataField.Kind().String())
+		field.Value = fmt.Sprintf("%.2f", dataField.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// print(field.Model + " of type " + dataField.Kind().String())
+		field.Value = fmt.Sprintf("%d", dataField.Int())
+	case reflect.Ptr:
+		// print(field.Model + " of type " + dataField.Kind().String())
+		switch field.Type {
+		case "date", "datetime-local":
+			field.Value = ""
+			ptr := unsafe.Pointer(dataField.Pointer())
+			if ptr != nil {
+				t := *(*time.Time)(ptr)
+				field.Value = t.Format(dateLayout(field))
+			}
+		case "number":
+			field.Value = ""
+			ptr := unsafe.Pointer(dataField.Pointer())
+			if ptr != nil {
+				if field.IsFloat {
+					v := *(*float64)(ptr)
+					field.Value = fmt.Sprintf("%f", v)
+				} else {
+					v := *(*int)(ptr)
+					field.Value = fmt.Sprintf("%d", v)
+				}
+			}
+		default:
+			field.Value = dataField.String()
+		}
+	case reflect.String:
+		field.Value = dataField.String()
+		if t, err := time.Parse(time.RFC3339, field.Value); err == nil && (field.Type == "date" || field.Type == "datetime-local") {
+			field.Value = t.Format(dateLayout(field))
+		}
+	case reflect.Bool:
+		field.Checked = dataField.Bool()
+	case reflect.Slice:
+		field.Value = listValue(dataField)
+	case reflect.Struct:
+		if t, ok := toTime(dataField.Interface()); ok {
+			field.Value = t.Format(dateLayout(field))
+		}
+	default:
+		// print(field.Model + " of type " + dataField.Kind().String())
+		field.Value = dataField.String()
+	}
+	if field.Enum != nil {
+		field.selectKey(enumKey(field, field.Value))
+	}
+	if f.DisplayMode {
+		field.Display = displayValue(field, dataField)
+	}
+}
+
+// Scope any swappers and their panels to this form
+func (f *EditForm) scope() {
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if field.Type == "swapper" && field.Swapper != nil {
+				field.Swapper.Root = f.Root
+				field.Swapper.root = f.root
+				field.Swapper.theme = f.CurrentTheme()
+				for _, p := range field.Swapper.Panels {
+					p.Root = f.Root
+					p.root = f.root
+					p.policy = f.Policy
+					p.form = f
+				}
+			}
+		}
+	}
+}
+
+// Wire up the rendered markup to the form's callbacks and data
+func (f *EditForm) decorate(data interface{}) {
+	// keep masked inputs in their masks as they are typed into
+	f.decorateMasks()
+	// tell the field change subscriptions about changes on the form, and bind live fields
+	f.decorateChanges()
+
+	if f.Root == nil {
+		// not in a browser, so there is nothing to wire up
+		return
+	}
+
+	// lay the form out again when the window changes to another breakpoint
+	f.decorateResize()
 
 	// If there are any photo fields, render them in here
 	for _, row := range f.Rows {
 		for _, field := range row.Fields {
 			if field.Model != "" && field.Type == "photo" {
-				dataField := reflect.Indirect(reflect.ValueOf(data)).FieldByName(fmt.Sprintf("%s", field.Model))
+				dataField := modelValue(data, field.Model)
 				// print("post processing photo field", field.Model, "of type", dataField.Kind().String())
 
-				el := doc.QuerySelector("[name=" + field.Model + "Preview]")
+				el := f.query(`[name="` + field.Model + `Preview"]`)
 				if el != nil {
 
 					tt := ""
@@ -989,21 +1097,22 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 
 					} // switch statement end
 
+					// a photo that was kept over a change of display mode, but not saved yet
+					if field.Value != "" {
+						tt = field.Value
+					}
+
 					// Get the hint field
-					elh := doc.QuerySelector("[name=" + field.Model + "PreviewHint]")
+					elh := f.query(`[name="` + field.Model + `PreviewHint"]`)
 					if tt == "" {
-						el.(*dom.HTMLImageElement).Src = ""
-						el.Class().Add("hidden")
-						if elh != nil {
-							elh.Class().Add("hidden")
-						}
+						el.(*dom.HTMLImageElement).Set("src", "")
+						showElement(f.CurrentTheme(), el, false)
+						showElement(f.CurrentTheme(), elh, false)
 						// }
 					} else {
-						el.Class().Remove("hidden")
-						if elh != nil {
-							elh.Class().Remove("hidden")
-						}
-						el.(*dom.HTMLImageElement).Src = tt
+						showElement(f.CurrentTheme(), el, true)
+						showElement(f.CurrentTheme(), elh, true)
+						el.(*dom.HTMLImageElement).Set("src", tt)
 					}
 
 					// Now ... if this form has a save function, then allow a confirmation click on the preview image
@@ -1011,11 +1120,13 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 
 					if f.SaveCB != nil && field.PhotoUpload {
 						print("adding a click handler to the preview to call the save event")
-						el.AddEventListener("click", false, f.SaveCB)
+						f.listeners.add(el, "click", f.SaveCB)
 					}
-					if f.AttachCB != nil {
+					if field.PhotoUpload && field.PhotoOpts != nil {
+						f.decoratePhoto(field, el.(*dom.HTMLImageElement))
+					} else if f.AttachCB != nil {
 						print("adding a change handler to the photo field")
-						doc.QuerySelector("[name="+field.Model+"]").AddEventListener("change", false, func(evt dom.Event) {
+						f.listeners.add(f.query(`[name="`+field.Model+`"]`), "change", func(evt dom.Event) {
 							go f.AttachCB()
 						})
 					}
@@ -1027,6 +1138,20 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 		}
 	}
 
+	// Wire up any multi file upload and signature fields
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if field.Model != "" {
+				switch field.Type {
+				case "files":
+					f.decorateFiles(field)
+				case "signature":
+					f.decorateSignature(field)
+				}
+			}
+		}
+	}
+
 	// if f.CancelCB == nil {
 	// 	print("Error - No cancel callback")
 	// 	return
@@ -1037,7 +1162,7 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 	// }
 
 	// If there is a focusfield, then focus on it
-	if el := doc.QuerySelector("#focusme"); el != nil {
+	if el := f.query("[data-focusme]"); el != nil {
 		print("setting focus on", el)
 		el.(*dom.HTMLInputElement).Focus()
 	}
@@ -1045,51 +1170,38 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 	// plug in cancel callbacks
 	if f.CancelCB != nil {
 
-		if el := doc.QuerySelector("#legend"); el != nil {
-			el.AddEventListener("click", false, f.CancelCB)
+		if el := f.query(f.id("legend")); el != nil {
+			f.listeners.add(el, "click", f.CancelCB)
 		}
 
-		if el := doc.QuerySelector(".md-close"); el != nil {
-			el.AddEventListener("click", false, f.CancelCB)
+		if el := f.query(".md-close"); el != nil {
+			f.listeners.add(el, "click", f.CancelCB)
 		}
-
-		// if el := doc.QuerySelector(".grid-form"); el != nil {
-		// 	el.AddEventListener("keyup", false, func(evt dom.Event) {
-		// 		kevt, isKB := evt.(*dom.KeyboardEvent)
-		// 		if isKB && kevt != nil {
-		// 			if kevt.KeyCode == 27 {
-		// 				evt.PreventDefault()
-		// 				if f.CancelCB != nil {
-		// 					f.CancelCB(evt)
-		// 				}
-		// 			}
-		// 		}
-		// 	})
-		// }
 	}
 
 	if f.DeleteCB != nil {
-		if el := doc.QuerySelector(".md-confirm-del"); el != nil {
-			el.AddEventListener("click", false, f.DeleteCB)
+		if el := f.query(".md-confirm-del"); el != nil {
+			f.listeners.add(el, "click", f.DeleteCB)
 		}
 
-		if el := doc.QuerySelector(".data-del-btn"); el != nil {
-			el.AddEventListener("click", false, func(evt dom.Event) {
-				doc.QuerySelector("#confirm-delete").Class().Add("md-show")
+		if el := f.query(".data-del-btn"); el != nil {
+			f.listeners.add(el, "click", func(evt dom.Event) {
+				f.showDelete(true)
 			})
 		}
 
-		if el := doc.QuerySelector(".md-close-del"); el != nil {
-			el.AddEventListener("click", false, func(evt dom.Event) {
-				doc.QuerySelector("#confirm-delete").Class().Remove("md-show")
+		if el := f.query(".md-close-del"); el != nil {
+			f.listeners.add(el, "click", func(evt dom.Event) {
+				f.showDelete(false)
 			})
 		}
 
-		if el := doc.QuerySelector("#confirm-delete"); el != nil {
-			el.AddEventListener("keyup", false, func(evt dom.Event) {
+		if el := f.query(f.id("confirm-delete")); el != nil {
+			trapFocus(&f.listeners, el)
+			f.listeners.add(el, "keyup", func(evt dom.Event) {
 				if evt.(*dom.KeyboardEvent).KeyCode == 27 {
 					evt.PreventDefault()
-					doc.QuerySelector("#confirm-delete").Class().Remove("md-show")
+					f.showDelete(false)
 				}
 			})
 		}
@@ -1098,64 +1210,97 @@ func (f *EditForm) Render(template string, selector string, data interface{}) {
 
 	// plug in the save callback
 	if f.SaveCB != nil {
-		if el := doc.QuerySelector(".md-save"); el != nil {
-			el.AddEventListener("click", false, f.SaveCB)
+		if el := f.query(".md-save"); el != nil {
+			f.listeners.add(el, "click", f.SaveCB)
 		}
 	}
 
 	// plug in the change event
 	if f.ChangeCB != nil {
-		if el := doc.QuerySelector("form"); el != nil {
-			el.AddEventListener("change", false, f.ChangeCB)
+		if el := f.query("form"); el != nil {
+			f.listeners.add(el, "change", f.ChangeCB)
 		}
 	}
 
 	// plug in the print callback
 	if f.PrintCB != nil {
 		// assume that if screen width is super small, then they are on a mobile, and therefore dont have print access
-		if el := doc.QuerySelector(".data-print-btn"); el != nil {
-			el.AddEventListener("click", false, f.PrintCB)
+		if el := f.query(".data-print-btn"); el != nil {
+			f.listeners.add(el, "click", f.PrintCB)
 		}
 	}
 
-	// Scroll to the top
-	w.Scroll(0, 0)
+	// plug in the edit button, to change from display mode to edit mode
+	if el := f.query(".data-edit-btn"); el != nil {
+		f.listeners.add(el, "click", func(evt dom.Event) {
+			f.SetDisplayMode(false)
+		})
+	}
+
+	// plug in the keyboard shortcuts
+	f.decorateKeys()
+	activateButtons(&f.listeners, f.Root)
+}
+
+// Scroll to the top, after the form is first rendered
+func scrollToTop(root dom.Element) {
+	if root == nil {
+		return
+	}
+	dom.GetWindow().Scroll(0, 0)
 }
 
-// Add actions
+// Add actions. The selector is looked up inside the form first, then in the document.
+// The old "#action-grid" selector finds the grid of this form
 func (f *EditForm) ActionGrid(template string, selector string, id interface{}, cb func(string)) {
-	ActionGrid(template, selector, id, cb)
+	selector = scopedSelector(f.UID, selector)
+	if f.query(selector) == nil {
+		ActionGrid(template, selector, id, cb)
+		return
+	}
+	actionGrid(f.Root, &f.listeners, f.CurrentTheme(), template, selector, id, cb)
 }
 
+// ActionGrid renders the action grid into the selector in the document. The old
+// "#action-grid" selector finds the grid by its class
 func ActionGrid(template string, selector string, id interface{}, cb func(string)) {
-	// print("add action grid")
-	w := dom.GetWindow()
-	doc := w.Document()
+	if querySelector(nil, selector) == nil {
+		selector = scopedSelector("", selector)
+	}
+	actionGrid(nil, nil, theme, template, selector, id, cb)
+}
 
-	el := doc.QuerySelector(selector)
+// Render the action grid into the selector inside root, and wire up the items,
+// keeping track of the listeners if l is not nil
+func actionGrid(root dom.Element, l *listeners, t Theme, template string, selector string, id interface{}, cb func(string)) {
+	// print("add action grid")
+	el := querySelector(root, selector)
 	if el == nil {
 		print("Could not find selector", selector)
 		return
 	}
-	el.Class().Remove("hidden")
+	showElement(t, el, true)
 
-	renderTemplate(template, selector, id)
-	for _, ai := range doc.QuerySelectorAll(".action__item") {
+	renderTemplateEl(template, el, id)
+	for _, ai := range el.QuerySelectorAll(".action__item") {
 		url := ai.(*dom.HTMLDivElement).GetAttribute("url")
 		if url != "" {
-			ai.AddEventListener("click", false, func(evt dom.Event) {
+			click := func(evt dom.Event) {
 				url := evt.CurrentTarget().GetAttribute("url")
 				cb(url)
-			})
+			}
+			if l != nil {
+				l.add(ai, "click", click)
+			} else {
+				ai.AddEventListener("click", false, click)
+			}
 		}
 	}
 }
 
 // Programmatically reset the Form title
 func (f *EditForm) SetTitle(title string) {
-	w := dom.GetWindow()
-	doc := w.Document()
-	el := doc.QuerySelector("#titletext")
+	el := f.query(f.id("titletext"))
 	// print("setting element", el, " was =", el.InnerHTML())
 	el.SetInnerHTML(title)
 }
@@ -1167,8 +1312,11 @@ func (f *EditForm) Bind(data interface{}) {
 
 func (f *EditForm) BindPart(data interface{}, all bool) {
 	// print("binding fields to data")
-	w := dom.GetWindow()
-	doc := w.Document()
+
+	if m, ok := dataMap(data); ok {
+		f.bindMap(m)
+		return
+	}
 
 	// Make sure the type of v is a pointer to a struct.
 	ptrType := reflect.TypeOf(data)
@@ -1189,202 +1337,143 @@ func (f *EditForm) BindPart(data interface{}, all bool) {
 
 	for _, row := range f.Rows {
 		for _, field := range row.Fields {
-
 			// If its a display only field, or a custom div
-			// then dont bother binding it == much speed ++ safety
-			if field.Readonly {
+			// then dont bother binding it == much speed ++ safety.
+			// The policy is checked as well, whatever the DOM says
+			if field.Readonly || f.access(field) != Editable || field.Type == "div" {
 				continue
 			}
-			if field.Type == "div" {
+			if field.Type == "swapper" {
+				// Swapper has a slice of panels, each with a slice of rows of fields
+				if all {
+					for _, p := range field.Swapper.Panels {
+						if p.BindWithForm {
+							for _, r := range p.Rows {
+								for _, sf := range r.Fields {
+									if sf.Readonly || f.access(sf) != Editable {
+										continue
+									}
+									f.bindField(ptrVal, sf)
+								}
+							}
+						}
+					}
+				}
 				continue
 			}
+			f.bindField(ptrVal, field)
+		}
+	}
 
-			name := `[name="` + field.Model + `"]`
-			el := doc.QuerySelector(name)
-			dataField := reflect.Indirect(ptrVal).FieldByName(field.Model)
-
-			// print("field =", field)
-			switch field.Type {
-			case "photo":
-				// print("binding photo field")
-				// print("and dataField must be a struct FileField at this stage")
-				k := dataField.Kind()
-
-				print("model is", field.Model, "dataField kind is", k, k.String())
-
-				if field.PhotoUpload {
-
-					img := doc.QuerySelector(`[name="` + field.Model + `Preview"]`).(*dom.HTMLImageElement)
-					print("img field", img)
-					dasSrc := img.GetAttribute("src")
-
-					photoDataField := dataField.FieldByName("Data")
-					// photoDataField := reflect.Indirect(reflect.ValueOf(dataField)).FieldByName("Data")
-					// print("set datafld from dasSrc")
-					setFromString(photoDataField, dasSrc)
-
-					// get the filename from the inputfield
-					inputField := doc.QuerySelector(fmt.Sprintf("[name=%s]", field.Model)).(*dom.HTMLInputElement)
-					// print("filename may =", inputField.Value)
-					lastSlash := strings.LastIndex(inputField.Value, `\`)
-					// print("last slash", lastSlash)
-					fileName := inputField.Value
-					if lastSlash > -1 {
-						fileName = fileName[lastSlash+1:]
-					}
-					// print("editform bind computed filename to be", fileName)
+}
 
-					fileNameField := dataField.FieldByName("Filename")
-					// print("fnf", fileNameField)
+// Read the DOM value of a field back into the data
+func (f *EditForm) bindField(ptrVal reflect.Value, field *EditField) {
+	name := `[name="` + field.Model + `"]`
+	el := f.find(name)
+	dataField := modelField(ptrVal, field.Model, true)
 
-					setFromString(fileNameField, fileName)
-				}
-			case "text":
-				setFromString(dataField, el.(*dom.HTMLInputElement).Value)
-			case "textarea":
-				setFromString(dataField, el.(*dom.HTMLTextAreaElement).Value)
-			case "select":
-				idx := el.(*dom.HTMLSelectElement).SelectedIndex
-				// print("here with field", field)
-				// print("datafield", dataField)
-				// print("idx", idx)
-				// print("opts key", field.Options[idx])
-				setFromInt(dataField, field.Options[idx].Key)
-			case "groupselect":
-				idx := el.(*dom.HTMLSelectElement).SelectedIndex
-				setFromInt(dataField, idx)
-			case "checkbox":
-				//print("checkbox binding into", dataField)
-				//print("with checked", el.(*dom.HTMLInputElement).Checked)
-				//print("with value", el.(*dom.HTMLInputElement).Value)
-				setFromBool(dataField, el.(*dom.HTMLInputElement).Checked)
-			case "radio":
-				els := doc.QuerySelectorAll(name)
-				for _, rel := range els {
-					ie := rel.(*dom.HTMLInputElement)
-					if ie.Checked {
-						v, err := strconv.Atoi(ie.Value)
-						if err != nil {
-							print("strconv err from ", ie.Value, err.Error())
-						} else {
-							setFromInt(dataField, v)
-						}
-						break
-					}
-				}
-			case "number":
-				ie := el.(*dom.HTMLInputElement)
-				// print("number field binding", field)
-				if field.IsFloat {
-					v, ferr := strconv.ParseFloat(ie.Value, 64)
-					if ferr != nil {
-						print("strconv.ParseFloat err ", ferr.Error())
-					}
-					setFromFloat(dataField, v)
-				} else {
-					v, ferr := strconv.Atoi(ie.Value)
-					if ferr != nil {
-						print("strconv.Atoi err ", ferr.Error())
-					}
-					setFromInt(dataField, v)
-				}
-			case "date":
-				ie := el.(*dom.HTMLInputElement)
-				setFromDate(dataField, ie.Value)
-				// print("TODO - bind from date field", ie.Value)
-			case "div":
-				// is just a placeholder, dont bind it
-			case "swapper":
-				// Swapper has a slice of panels
-				if all {
-					for _, p := range field.Swapper.Panels {
-						if p.BindWithForm {
+	switch field.Type {
+	case "text", "textarea", "select", "groupselect", "checkbox", "number", "date", "datetime-local":
+		if el == nil {
+			print("form: there is no element for", field.Model, "to bind")
+			return
+		}
+	}
 
-							// Panel has a slice of rows
-							for _, r := range p.Rows {
-								// Row has a slice of fields
-								for _, f := range r.Fields {
-									name := `[name="` + f.Model + `"]`
-									el := doc.QuerySelector(name)
-									dataField := reflect.Indirect(ptrVal).FieldByName(f.Model)
-									switch f.Type {
-									case "text":
-										// print("f", f)
-										// print("datafield", dataField)
-										setFromString(dataField, el.(*dom.HTMLInputElement).Value)
-									case "textarea":
-										setFromString(dataField, el.(*dom.HTMLTextAreaElement).Value)
-									case "select":
-										idx := el.(*dom.HTMLSelectElement).SelectedIndex
-										setFromInt(dataField, f.Options[idx].Key)
-									case "checkbox":
-										setFromString(dataField, el.(*dom.HTMLInputElement).Value)
-									case "radio":
-										els := doc.QuerySelectorAll(name)
-										for _, rel := range els {
-											print("having a look at rel", rel)
-											ie := rel.(*dom.HTMLInputElement)
-											if ie.Checked {
-												// print("swapper radio", name, "value =", ie.Value)
-												v, _ := strconv.Atoi(ie.Value)
-												setFromInt(dataField, v)
-												break
-											}
-										}
-									case "number":
-										print("here with ", f, field)
-										ie, ok := el.(*dom.HTMLInputElement)
-										if ok {
-
-											if field.IsFloat {
-												if ie.Value == "" {
-													setFromFloat(dataField, 0.0)
-												} else {
-													v, ferr := strconv.ParseFloat(ie.Value, 64)
-													if ferr != nil {
-														print("strconv.ParseFloat err ", ferr.Error(), "field=", f, "val=", ie.Value)
-													}
-													setFromFloat(dataField, v)
-												}
-											} else {
-												if ie.Value == "" {
-													setFromInt(dataField, 0)
-												} else {
-													v, ferr := strconv.Atoi(ie.Value)
-													if ferr != nil {
-														print("strconv.Atoi err ", ferr.Error(), "val =", ie.Value, "model =", f.Model, "field =", f)
-													}
-													setFromInt(dataField, v)
-												}
-											}
-										} else {
-											print("cast didnt work")
-										}
-									case "date":
-										ie := el.(*dom.HTMLInputElement)
-										setFromDate(dataField, ie.Value)
-										// print("TODO - bind swapper from date field", ie.Value)
-									}
+	// print("field =", field)
+	switch field.Type {
+	case "photo":
+		// print("binding photo field")
+		// print("and dataField must be a struct FileField at this stage")
+		k := dataField.Kind()
 
-								}
-							} // for rows of panel
-						} // if panel is allowed to bind
+		print("model is", field.Model, "dataField kind is", k, k.String())
 
-					} // foreach panel in the swapper
+		if field.PhotoUpload && k == reflect.Struct {
+			ff := f.photoFile(field)
+			setFromString(dataField.FieldByName("Data"), ff.Data)
+			setFromString(dataField.FieldByName("Filename"), ff.Filename)
+		}
+	case "files":
+		setFromFiles(dataField, field.Files)
+	case "signature":
+		setFromSignature(dataField, field.Value)
+	case "text":
+		setFromString(dataField, field.bindText(el.Value()))
+	case "textarea":
+		if field.ListOf != "" {
+			setFromList(dataField, el.Value())
+		} else {
+			setFromString(dataField, el.Value())
+		}
+	case "select":
+		idx := el.SelectedIndex()
+		if idx < 0 || idx >= len(field.Options) {
+			break
+		}
+		// print("here with field", field)
+		// print("datafield", dataField)
+		// print("idx", idx)
+		// print("opts key", field.Options[idx])
+		if field.Enum != nil {
+			v, _ := field.optionValue(field.Options[idx].Key).(string)
+			setFromString(dataField, v)
+		} else {
+			setFromInt(dataField, field.Options[idx].Key)
+		}
+	case "groupselect":
+		idx := el.SelectedIndex()
+		setFromInt(dataField, idx)
+	case "checkbox":
+		//print("checkbox binding into", dataField)
+		//print("with checked", el.Checked())
+		//print("with value", el.Value())
+		setFromBool(dataField, el.Checked())
+	case "radio":
+		els := f.findAll(name)
+		for _, rel := range els {
+			ie := rel
+			if ie.Checked() {
+				v, err := strconv.Atoi(ie.Value())
+				if err != nil {
+					print("strconv err from ", ie.Value(), err.Error())
+				} else {
+					setFromInt(dataField, v)
 				}
-			default:
-				print("TODO - bind from ", field.Type)
+				break
+			}
+		}
+	case "number":
+		ie := el
+		// print("number field binding", field)
+		if field.IsFloat {
+			v, ferr := strconv.ParseFloat(ie.Value(), 64)
+			if ferr != nil {
+				print("strconv.ParseFloat err ", ferr.Error())
 			}
+			setFromFloat(dataField, v)
+		} else {
+			v, ferr := strconv.Atoi(ie.Value())
+			if ferr != nil {
+				print("strconv.Atoi err ", ferr.Error())
+			}
+			setFromInt(dataField, v)
 		}
+	case "date", "datetime-local":
+		ie := el
+		setFromDate(dataField, ie.Value())
+		// print("TODO - bind from date field", ie.Value())
+	case "div":
+		// is just a placeholder, dont bind it
+	default:
+		print("TODO - bind from ", field.Type)
 	}
-
 }
 
 // Read the DOM values of each field back into the data, just for this panel
 func (f *Panel) Bind(data interface{}) {
 	// print("binding fields to data")
-	w := dom.GetWindow()
-	doc := w.Document()
 
 	// Make sure the type of v is a pointer to a struct.
 	ptrType := reflect.TypeOf(data)
@@ -1403,85 +1492,19 @@ func (f *Panel) Bind(data interface{}) {
 		return
 	}
 
-	for _, row := range f.Rows {
-		for _, field := range row.Fields {
-
-			// If its a display only field, or a custom div
-			// then dont bother binding it == much speed ++ safety
-			if field.Readonly {
-				continue
-			}
-			if field.Type == "div" {
-				continue
-			}
+	if f.form == nil {
+		print("ERROR: Bind called on panel", f.Name, "before the form is rendered")
+		return
+	}
 
-			name := `[name="` + f.Name + `-` + field.Model + `"]`
-			// print("looking for ", name)
-			el := doc.QuerySelector(name)
-			dataField := reflect.Indirect(ptrVal).FieldByName(field.Model)
-
-			switch field.Type {
-			case "text":
-				setFromString(dataField, el.(*dom.HTMLInputElement).Value)
-			case "textarea":
-				setFromString(dataField, el.(*dom.HTMLTextAreaElement).Value)
-			case "select":
-				idx := el.(*dom.HTMLSelectElement).SelectedIndex
-				if idx < 0 {
-					setFromInt(dataField, 0)
-				} else {
-					// print("here with field", field)
-					// print("datafield", dataField)
-					// print("idx", idx)
-					// print("opts key", field.Options[idx])
-					setFromInt(dataField, field.Options[idx].Key)
-				}
-			case "groupselect":
-				idx := el.(*dom.HTMLSelectElement).SelectedIndex
-				setFromInt(dataField, idx)
-			case "checkbox":
-				//print("checkbox binding into", dataField)
-				//print("with checked", el.(*dom.HTMLInputElement).Checked)
-				//print("with value", el.(*dom.HTMLInputElement).Value)
-				setFromBool(dataField, el.(*dom.HTMLInputElement).Checked)
-			case "radio":
-				els := doc.QuerySelectorAll(name)
-				for _, rel := range els {
-					ie := rel.(*dom.HTMLInputElement)
-					if ie.Checked {
-						v, _ := strconv.Atoi(ie.Value)
-						setFromInt(dataField, v)
-						break
-					}
-				}
-			case "number":
-				ie := el.(*dom.HTMLInputElement)
-				if ie.Value != "" {
-					// print("number field binding", field)
-					if field.IsFloat {
-						v, ferr := strconv.ParseFloat(ie.Value, 64)
-						if ferr != nil {
-							print("strconv.ParseFloat err ", ferr.Error())
-						}
-						setFromFloat(dataField, v)
-					} else {
-						v, ferr := strconv.Atoi(ie.Value)
-						if ferr != nil {
-							print("strconv.Atoi err ", ferr.Error())
-						}
-						setFromInt(dataField, v)
-					}
-				}
-			case "date":
-				ie := el.(*dom.HTMLInputElement)
-				setFromDate(dataField, ie.Value)
-				print("TODO - bind from date field", ie.Value)
-			case "div":
-				// is just a placeholder, dont bind it
-			default:
-				print("TODO - bind from ", field.Type)
-			}
+	for _, field := range f.fields() {
+		// If its a display only field, or a custom div
+		// then dont bother binding it == much speed ++ safety.
+		// The policy is checked as well, whatever the DOM says
+		if field.Readonly || f.policy.Access(field.Model) != Editable || field.Type == "div" {
+			continue
 		}
+		f.form.bindField(ptrVal, field)
 	}
 
 }
@@ -1560,7 +1583,7 @@ const (
 
 func setFromDate(target reflect.Value, str string) {
 
-	thedate, _ := time.Parse(rfc3339DateLayout, str)
+	thedate, _ := parseDate(str)
 	// print("Parse", str, "as", thedate.String())
 
 	k := target.Kind()
@@ -1635,9 +1658,7 @@ func (f *EditForm) Get(model string) dom.Element {
 		print("ERROR: Get(" + model + ") called Before form is rendered")
 		return nil
 	}
-	w := dom.GetWindow()
-	doc := w.Document()
-	el := doc.QuerySelector(fmt.Sprintf("[name=%s]", model))
+	el := f.query(fmt.Sprintf(`[name="%s"]`, model))
 	if el == nil {
 		print("Error: Cant find field", model)
 	}
@@ -1647,7 +1668,7 @@ func (f *EditForm) Get(model string) dom.Element {
 func (f *EditForm) ReadOnly(model string, r bool) {
 	el := f.Get(model)
 	if el != nil {
-		el.(*dom.HTMLInputElement).ReadOnly = r
+		el.(*dom.HTMLInputElement).Set("readOnly", r)
 	}
 }
 
@@ -1661,17 +1682,11 @@ func (f *EditForm) Class(model string) *dom.TokenList {
 }
 
 func (f *EditForm) Hide(model string) {
-	cl := f.Class(model)
-	if cl != nil {
-		cl.Add("hidden")
-	}
+	showElement(f.CurrentTheme(), f.Get(model), false)
 }
 
 func (f *EditForm) Show(model string) {
-	cl := f.Class(model)
-	if cl != nil {
-		cl.Remove("hidden")
-	}
+	showElement(f.CurrentTheme(), f.Get(model), true)
 }
 
 func (f *EditForm) GetRow(r int) *dom.HTMLDivElement {
@@ -1743,7 +1758,7 @@ func (f *EditForm) FocusSelect(model string) {
 func (f *EditForm) OnEvent(model string, event string, cb func(dom.Event)) {
 	el := f.Get(model)
 	if el != nil {
-		el.AddEventListener(event, false, cb)
+		f.listeners.add(el, event, cb)
 	}
 }
 
@@ -1753,12 +1768,16 @@ func (f *EditForm) AppendDiv(name string, class string) *dom.HTMLDivElement {
 	return div
 }
 
+// Insert a div into the container, looking for the container inside the form first
 func (f *EditForm) InsertDiv(name string, c string, before string) *dom.HTMLDivElement {
 	w := dom.GetWindow()
 	doc := w.Document()
 	div := doc.CreateElement("div").(*dom.HTMLDivElement)
 	div.SetID(name)
-	container := doc.QuerySelector(c)
+	container := f.query(c)
+	if container == nil {
+		container = doc.QuerySelector(c)
+	}
 	container.InsertBefore(div, container.QuerySelector(before))
 	return div
 }
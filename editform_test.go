@@ -0,0 +1,298 @@
+package formulate
+
+import (
+	"html/template"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-humble/temple/temple"
+)
+
+// Render into a fake document, using the templates on disk
+func setupFakeDOM(t *testing.T, body string) *fakeNode {
+	doc := newFakeDocument(body)
+	document = func() node { return doc }
+	Templates(func(name string) (*temple.Template, error) {
+		src, err := ioutil.ReadFile(name + ".tmpl")
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Parse(string(src))
+		if err != nil {
+			return nil, err
+		}
+		return &temple.Template{Template: tmpl}, nil
+	})
+	return doc
+}
+
+type testOption struct {
+	ID   int
+	Name string
+}
+
+type testJob struct {
+	Name        string
+	Notes       string
+	Status      int
+	Group       int
+	Urgent      bool
+	Priority    int
+	Hours       int
+	Rate        float64
+	Due         *time.Time
+	Attachments []FileField
+	Signature   string
+	Photo       FileField
+	Site        string
+	OnSite      bool
+	Visits      int
+	Ref         string
+}
+
+var testStatuses = []testOption{{1, "Open"}, {2, "Closed"}, {3, "Hold"}}
+
+func newTestForm(job *testJob) *EditForm {
+	f := EditForm{}
+	f.New("fa-wrench", "Job")
+
+	f.Row(2).
+		AddInput(1, "Name", "Name").
+		AddDisplay(1, "Ref", "Ref")
+	f.Row(1).
+		AddTextarea(1, "Notes", "Notes")
+	f.Row(3).
+		AddSelect(1, "Status", "Status", testStatuses, "ID", "Name", 1, job.Status).
+		AddGroupedSelect(1, "Group", "Group", []SelectGroup{
+			{Title: "A", Options: []SelectOption{{0, "Zero"}, {1, "One"}}},
+			{Title: "B", Options: []SelectOption{{2, "Two"}, {3, "Three"}}},
+		}, job.Group).
+		AddCheck(1, "Urgent", "Urgent")
+	f.Row(3).
+		AddRadio(1, "Priority", "Priority", testStatuses, "ID", "Name", job.Priority).
+		AddNumber(1, "Hours", "Hours", "1").
+		AddDecimal(1, "Rate", "Rate", 2, "0.01")
+	f.Row(1).
+		AddDate(1, "Due", "Due")
+	f.Row(3).
+		AddFiles(1, "Attachments", "Attachments", "", 0, 0).
+		AddSignature(1, "Signature", "Signature").
+		AddPhoto(1, "Photo", "Photo")
+
+	swapper := &Swapper{Name: "Where"}
+	p := swapper.AddPanel("Site")
+	p.AddRow(3).
+		AddInput(1, "Site", "Site").
+		AddCheck(1, "On Site", "OnSite").
+		AddNumber(1, "Visits", "Visits", "1")
+	swapper.AddPanel("Office").AddRow(1).AddDisplay(1, "Ref", "Ref")
+	f.Row(1).AddSwapper(1, "Where", swapper)
+	return &f
+}
+
+func fakeField(t *testing.T, doc *fakeNode, selector string) *fakeNode {
+	n := doc.QuerySelector(selector)
+	if n == nil {
+		t.Fatalf("no element matches %s", selector)
+	}
+	return n.(*fakeNode)
+}
+
+func TestEditFormRenderBindRoundTrip(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	due := time.Date(2017, 3, 14, 0, 0, 0, 0, time.UTC)
+	job := &testJob{
+		Name:        "Pump service",
+		Notes:       "Check the seals\nand the <valves>",
+		Status:      2,
+		Group:       2,
+		Urgent:      true,
+		Priority:    3,
+		Hours:       4,
+		Rate:        12.5,
+		Due:         &due,
+		Attachments: []FileField{{Filename: "a.pdf", Type: "application/pdf", Size: 10, Data: "data:application/pdf;base64,AAAA"}},
+		Signature:   "data:image/png;base64,AAAA",
+		Site:        "Depot",
+		OnSite:      true,
+		Visits:      2,
+		Ref:         "J-100",
+	}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+	if !f.IsRendered {
+		t.Fatal("form was not rendered")
+	}
+
+	// Binding an untouched form gives back the same data
+	got := &testJob{}
+	f.Bind(got)
+	want := *job
+	want.Ref = ""        // display only, so not bound
+	want.Photo.Data = "" // nothing uploaded
+	if got.Name != want.Name || got.Notes != want.Notes || got.Status != want.Status ||
+		got.Group != want.Group || got.Urgent != want.Urgent || got.Priority != want.Priority ||
+		got.Hours != want.Hours || got.Rate != want.Rate || got.Site != want.Site ||
+		got.OnSite != want.OnSite || got.Visits != want.Visits || got.Ref != want.Ref ||
+		got.Signature != want.Signature {
+		t.Errorf("untouched bind\n got %+v\nwant %+v", *got, want)
+	}
+	if got.Due == nil || !got.Due.Equal(due) {
+		t.Errorf("Due = %v, want %v", got.Due, due)
+	}
+	if len(got.Attachments) != 1 || got.Attachments[0] != job.Attachments[0] {
+		t.Errorf("Attachments = %+v, want %+v", got.Attachments, job.Attachments)
+	}
+
+	// Edit every field, then bind again
+	fakeField(t, doc, `[name="Name"]`).SetValue("Pump rebuild")
+	fakeField(t, doc, `[name="Notes"]`).SetValue("Done")
+	fakeField(t, doc, `[name="Status"]`).SetValue("3")
+	fakeField(t, doc, `[name="Group"]`).SetValue("1")
+	fakeField(t, doc, `[name="Urgent"]`).SetChecked(false)
+	fakeField(t, doc, `[name="Priority"][value="1"]`).SetChecked(true)
+	fakeField(t, doc, `[name="Hours"]`).SetValue("7")
+	fakeField(t, doc, `[name="Rate"]`).SetValue("99.25")
+	fakeField(t, doc, `[name="Due"]`).SetValue("2018-01-02")
+	fakeField(t, doc, `[name="Photo"]`).SetValue(`C:\fakepath\pump.jpg`)
+	fakeField(t, doc, `[name="PhotoPreview"]`).SetAttribute("src", "data:image/jpeg;base64,BBBB")
+	fakeField(t, doc, `[name="Site"]`).SetValue("Wharf")
+	fakeField(t, doc, `[name="OnSite"]`).SetChecked(false)
+	fakeField(t, doc, `[name="Visits"]`).SetValue("5")
+	f.GetField("Attachments").Files = nil
+	f.GetField("Signature").Value = ""
+
+	got = &testJob{}
+	f.Bind(got)
+	newDue := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	want = testJob{
+		Name:     "Pump rebuild",
+		Notes:    "Done",
+		Status:   3,
+		Group:    1,
+		Priority: 1,
+		Hours:    7,
+		Rate:     99.25,
+		Photo:    FileField{Filename: "pump.jpg", Data: "data:image/jpeg;base64,BBBB"},
+		Site:     "Wharf",
+		Visits:   5,
+	}
+	if got.Due == nil || !got.Due.Equal(newDue) {
+		t.Errorf("Due = %v, want %v", got.Due, newDue)
+	}
+	got.Due = nil
+	if len(got.Attachments) != 0 {
+		t.Errorf("Attachments = %+v, want none", got.Attachments)
+	}
+	got.Attachments = nil
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("edited bind\n got %+v\nwant %+v", *got, want)
+	}
+}
+
+func TestEditFormDisplayModeSkipsBind(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Rate: 1234.5, Urgent: true}
+	f := newTestForm(job)
+	f.DisplayMode = true
+	f.Render("edit-form", "#form", job)
+
+	if d := f.GetField("Rate").Display; d != "1,234.50" {
+		t.Errorf("Rate display = %q, want %q", d, "1,234.50")
+	}
+	if d := f.GetField("Urgent").Display; d != "Yes" {
+		t.Errorf("Urgent display = %q, want %q", d, "Yes")
+	}
+
+	got := &testJob{Name: "unchanged"}
+	f.Bind(got)
+	if got.Name != "unchanged" || got.Rate != 0 || got.Urgent {
+		t.Errorf("display mode form was bound: %+v", *got)
+	}
+}
+
+func TestSwapperSelect(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Status: 1, Priority: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+
+	sw := f.Rows[len(f.Rows)-1].Fields[0].Swapper
+	site := fakeField(t, doc, `[name="Where-Site"]`)
+	office := fakeField(t, doc, `[name="Where-Office"]`)
+
+	sw.Select(1)
+	if sw.Selected != 1 || sw.Current().Name != "Office" {
+		t.Errorf("selected %d, want 1", sw.Selected)
+	}
+	if !office.HasClass("swapper-show") || office.GetAttribute("aria-hidden") != "" {
+		t.Errorf("office panel not shown: %v", office.attrs)
+	}
+	if site.HasClass("swapper-show") || site.GetAttribute("aria-hidden") != "true" {
+		t.Errorf("site panel not hidden: %v", site.attrs)
+	}
+
+	sw.SelectByName("Site")
+	if sw.Selected != 0 || !site.HasClass("swapper-show") || office.HasClass("swapper-show") {
+		t.Errorf("SelectByName did not swap the panels back")
+	}
+}
+
+func TestPanelBind(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f := &EditForm{}
+	f.New("fa-wrench", "Job")
+	f.Row(1).AddInput(1, "Name", "Name")
+	swapper := &Swapper{Name: "Where"}
+	p := swapper.AddPanel("Site")
+	p.AddRow(3).
+		AddInput(1, "Site", "Site").
+		AddCheck(1, "On Site", "OnSite").
+		AddNumber(1, "Visits", "Visits", "1")
+	p.AddRow(3).
+		AddSelect(1, "Status", "Status", testStatuses, "ID", "Name", 1, 0).
+		AddRadio(1, "Priority", "Priority", testStatuses, "ID", "Name", 0).
+		AddTextarea(1, "Notes", "Notes")
+	p.AddRow(1).AddDisplay(1, "Ref", "Ref")
+	f.Row(1).AddSwapper(1, "Where", swapper)
+	f.Render("edit-form", "#form", &testJob{Name: "Pump service", Ref: "J-1"})
+
+	fakeField(t, doc, `[name="Site"]`).SetValue("Depot")
+	fakeField(t, doc, `[name="OnSite"]`).SetChecked(true)
+	fakeField(t, doc, `[name="Visits"]`).SetValue("3")
+	fakeField(t, doc, `[name="Status"]`).SetValue("2")
+	fakeField(t, doc, `[name="Priority"][value="3"]`).SetChecked(true)
+	fakeField(t, doc, `[name="Notes"]`).SetValue("Gate code & key")
+
+	got := &testJob{}
+	p.Bind(got)
+	want := testJob{Site: "Depot", OnSite: true, Visits: 3, Status: 2, Priority: 3, Notes: "Gate code & key"}
+	if got.Due != nil || got.Attachments != nil {
+		t.Fatalf("unexpected fields bound: %+v", *got)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("panel bind\n got %+v\nwant %+v", *got, want)
+	}
+
+	// a panel that is not on a rendered form binds nothing, rather than panic
+	(&Panel{Name: "Loose"}).Bind(got)
+}
+
+func TestScopedSelector(t *testing.T) {
+	cases := []struct {
+		uid, selector, want string
+	}{
+		{"formulate-3", "#action-grid", "#formulate-3-action-grid"},
+		{"formulate-3", "#confirm-delete", "#formulate-3-confirm-delete"},
+		{"", "#action-grid", ".action-grid"},
+		{"formulate-3", "#my-grid", "#my-grid"},
+		{"formulate-3", ".action-grid", ".action-grid"},
+	}
+	for _, c := range cases {
+		if got := scopedSelector(c.uid, c.selector); got != c.want {
+			t.Errorf("scopedSelector(%q, %q) = %q, want %q", c.uid, c.selector, got, c.want)
+		}
+	}
+}
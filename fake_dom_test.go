@@ -0,0 +1,397 @@
+package formulate
+
+import (
+	"html"
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// fakeNode is an in-memory node, parsed from the markup that the templates produce.
+// It knows just enough HTML to render, edit and bind the forms
+type fakeNode struct {
+	tag       string // "" for a text node
+	attrs     map[string]string
+	text      string
+	value     string
+	dirty     bool // value has been set, rather than coming from the markup
+	cursor    int  // the selection start, which setting the value moves to the end
+	checked   bool
+	parent    *fakeNode
+	children  []*fakeNode
+	listeners map[string][]func(dom.Event)
+}
+
+var voidTags = map[string]bool{
+	"input": true, "img": true, "br": true, "hr": true, "meta": true, "link": true,
+}
+
+// newFakeDocument returns a document with a body holding the given markup
+func newFakeDocument(body string) *fakeNode {
+	doc := &fakeNode{tag: "#document", attrs: map[string]string{}}
+	b := &fakeNode{tag: "body", attrs: map[string]string{}, parent: doc}
+	doc.children = []*fakeNode{b}
+	b.SetInnerHTML(body)
+	return doc
+}
+
+// parseHTML reads the markup into a tree under parent
+func parseHTML(parent *fakeNode, src string) {
+	stack := []*fakeNode{parent}
+	top := func() *fakeNode { return stack[len(stack)-1] }
+	appendChild := func(n *fakeNode) {
+		n.parent = top()
+		n.parent.children = append(n.parent.children, n)
+	}
+
+	for len(src) > 0 {
+		switch {
+		case strings.HasPrefix(src, "<!--"):
+			end := strings.Index(src, "-->")
+			if end < 0 {
+				return
+			}
+			src = src[end+3:]
+
+		case strings.HasPrefix(src, "</"):
+			end := strings.Index(src, ">")
+			if end < 0 {
+				return
+			}
+			tag := strings.ToLower(strings.TrimSpace(src[2:end]))
+			src = src[end+1:]
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].tag == tag {
+					stack = stack[:i]
+					break
+				}
+			}
+
+		case len(src) > 1 && src[0] == '<' && isLetter(src[1]):
+			n, rest, selfClosing := parseTag(src)
+			src = rest
+			appendChild(n)
+			switch {
+			case n.tag == "textarea":
+				end := strings.Index(src, "</textarea")
+				if end < 0 {
+					end = len(src)
+				}
+				n.text = html.UnescapeString(src[:end])
+				src = src[end:]
+			case !selfClosing && !voidTags[n.tag]:
+				stack = append(stack, n)
+			}
+
+		default:
+			end := strings.Index(src[1:], "<")
+			if end < 0 {
+				end = len(src)
+			} else {
+				end++
+			}
+			if t := src[:end]; strings.TrimSpace(t) != "" {
+				appendChild(&fakeNode{text: html.UnescapeString(t)})
+			}
+			src = src[end:]
+		}
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parseTag reads an opening tag and its attributes
+func parseTag(src string) (*fakeNode, string, bool) {
+	n := &fakeNode{attrs: map[string]string{}}
+	i := 1
+	for i < len(src) && !strings.ContainsRune(" \t\r\n/>", rune(src[i])) {
+		i++
+	}
+	n.tag = strings.ToLower(src[1:i])
+
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '>':
+			n.init()
+			return n, src[i+1:], false
+		case c == '/' && i+1 < len(src) && src[i+1] == '>':
+			n.init()
+			return n, src[i+2:], true
+		case strings.ContainsRune(" \t\r\n/", rune(c)):
+			i++
+		default:
+			start := i
+			for i < len(src) && !strings.ContainsRune(" \t\r\n=/>", rune(src[i])) {
+				i++
+			}
+			name := strings.ToLower(src[start:i])
+			value := ""
+			if i < len(src) && src[i] == '=' {
+				i++
+				if i < len(src) && (src[i] == '"' || src[i] == '\'') {
+					q := src[i]
+					end := strings.IndexByte(src[i+1:], q)
+					if end < 0 {
+						end = len(src) - i - 1
+					}
+					value = src[i+1 : i+1+end]
+					i += end + 2
+				} else {
+					start := i
+					for i < len(src) && !strings.ContainsRune(" \t\r\n>", rune(src[i])) {
+						i++
+					}
+					value = src[start:i]
+				}
+			}
+			n.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	n.init()
+	return n, "", false
+}
+
+// Set up the state that the markup gives an element
+func (n *fakeNode) init() {
+	_, n.checked = n.attrs["checked"]
+}
+
+func (n *fakeNode) matches(selector string) bool {
+	if n.tag == "" {
+		return false
+	}
+	s := selector
+	// optional tag name
+	i := 0
+	for i < len(s) && (isLetter(s[i]) || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	if i > 0 && strings.ToLower(s[:i]) != n.tag {
+		return false
+	}
+	s = s[i:]
+
+	for s != "" {
+		switch s[0] {
+		case '#', '.':
+			end := 1
+			for end < len(s) && !strings.ContainsRune("#.[", rune(s[end])) {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			name := strings.Replace(s[1:end], `\`, "", -1)
+			if s[0] == '#' && n.attrs["id"] != name {
+				return false
+			}
+			if s[0] == '.' && !n.HasClass(name) {
+				return false
+			}
+			s = s[end:]
+		case '[':
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return false
+			}
+			cond := s[1:end]
+			s = s[end+1:]
+			if eq := strings.Index(cond, "="); eq >= 0 {
+				want := strings.Trim(cond[eq+1:], `"'`)
+				if v, ok := n.attrs[cond[:eq]]; !ok || v != want {
+					return false
+				}
+			} else if _, ok := n.attrs[cond]; !ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (n *fakeNode) walk(fn func(*fakeNode)) {
+	for _, c := range n.children {
+		fn(c)
+		c.walk(fn)
+	}
+}
+
+func (n *fakeNode) QuerySelector(selector string) node {
+	if all := n.QuerySelectorAll(selector); len(all) > 0 {
+		return all[0]
+	}
+	return nil
+}
+
+func (n *fakeNode) QuerySelectorAll(selector string) []node {
+	nodes := []node{}
+	selectors := strings.Split(selector, ",")
+	n.walk(func(c *fakeNode) {
+		for _, s := range selectors {
+			if c.matches(strings.TrimSpace(s)) {
+				nodes = append(nodes, c)
+				return
+			}
+		}
+	})
+	return nodes
+}
+
+func (n *fakeNode) GetAttribute(name string) string        { return n.attrs[name] }
+func (n *fakeNode) SetAttribute(name string, value string) { n.attrs[name] = value }
+func (n *fakeNode) RemoveAttribute(name string)            { delete(n.attrs, name) }
+
+// The options of a select, including those inside optgroups
+func (n *fakeNode) options() []*fakeNode {
+	opts := []*fakeNode{}
+	n.walk(func(c *fakeNode) {
+		if c.tag == "option" {
+			opts = append(opts, c)
+		}
+	})
+	return opts
+}
+
+func (n *fakeNode) textContent() string {
+	if n.tag == "" {
+		return n.text
+	}
+	s := ""
+	for _, c := range n.children {
+		s += c.textContent()
+	}
+	return s
+}
+
+func (n *fakeNode) Value() string {
+	switch {
+	case n.dirty:
+		return n.value
+	case n.tag == "textarea":
+		return n.text
+	case n.tag == "select":
+		opts := n.options()
+		if idx := n.SelectedIndex(); idx >= 0 {
+			if v, ok := opts[idx].attrs["value"]; ok {
+				return v
+			}
+			return strings.TrimSpace(opts[idx].textContent())
+		}
+		return ""
+	}
+	return n.attrs["value"]
+}
+
+func (n *fakeNode) SetValue(value string) {
+	if n.tag == "select" {
+		for _, o := range n.options() {
+			if o.attrs["value"] == value {
+				o.attrs["selected"] = ""
+			} else {
+				delete(o.attrs, "selected")
+			}
+		}
+		return
+	}
+	n.value = value
+	n.dirty = true
+	n.cursor = len([]rune(value))
+}
+
+func (n *fakeNode) Checked() bool {
+	return n.checked
+}
+
+// Checking a radio unchecks the others of the same name
+func (n *fakeNode) SetChecked(checked bool) {
+	if checked && n.attrs["type"] == "radio" {
+		root := n
+		for root.parent != nil {
+			root = root.parent
+		}
+		root.walk(func(c *fakeNode) {
+			if c.attrs["type"] == "radio" && c.attrs["name"] == n.attrs["name"] {
+				c.checked = false
+			}
+		})
+	}
+	n.checked = checked
+}
+
+func (n *fakeNode) SelectionStart() int {
+	return n.cursor
+}
+
+func (n *fakeNode) SetSelectionRange(start int, end int) {
+	n.cursor = start
+}
+
+func (n *fakeNode) SelectedIndex() int {
+	opts := n.options()
+	for i, o := range opts {
+		if _, ok := o.attrs["selected"]; ok {
+			return i
+		}
+	}
+	if len(opts) > 0 {
+		return 0
+	}
+	return -1
+}
+
+func (n *fakeNode) classes() []string {
+	return strings.Fields(n.attrs["class"])
+}
+
+func (n *fakeNode) AddClass(class string) {
+	if !n.HasClass(class) {
+		n.attrs["class"] = strings.Join(append(n.classes(), class), " ")
+	}
+}
+
+func (n *fakeNode) RemoveClass(class string) {
+	keep := []string{}
+	for _, c := range n.classes() {
+		if c != class {
+			keep = append(keep, c)
+		}
+	}
+	n.attrs["class"] = strings.Join(keep, " ")
+}
+
+func (n *fakeNode) HasClass(class string) bool {
+	for _, c := range n.classes() {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *fakeNode) SetInnerHTML(src string) {
+	n.children = nil
+	parseHTML(n, src)
+}
+
+func (n *fakeNode) SetTextContent(text string) {
+	n.children = []*fakeNode{{text: text, parent: n}}
+}
+
+func (n *fakeNode) AddEventListener(event string, cb func(dom.Event)) func() {
+	if n.listeners == nil {
+		n.listeners = map[string][]func(dom.Event){}
+	}
+	n.listeners[event] = append(n.listeners[event], cb)
+	idx := len(n.listeners[event]) - 1
+	return func() {
+		n.listeners[event][idx] = nil
+	}
+}
+
+func (n *fakeNode) Element() dom.Element {
+	return nil
+}
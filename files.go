@@ -0,0 +1,252 @@
+package formulate
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Wire up the input, drop zone and file list of a multi file upload field
+func (f *EditForm) decorateFiles(field *EditField) {
+	f.paintFiles(field)
+	if field.Readonly {
+		return
+	}
+
+	if el := f.query(`[name="` + field.Model + `"]`); el != nil {
+		f.listeners.add(el, "change", func(evt dom.Event) {
+			input := evt.Target().Underlying()
+			fl := input.Get("files")
+			files := []dom.Value{}
+			for i := 0; i < fl.Length(); i++ {
+				files = append(files, fl.Index(i))
+			}
+			f.addFiles(field, files)
+			// clear the input, so that picking the same file again still fires a change
+			input.Set("value", "")
+		})
+	}
+
+	if drop := f.query(`[name="` + field.Model + `Drop"]`); drop != nil {
+		f.listeners.add(drop, "dragover", func(evt dom.Event) {
+			evt.PreventDefault()
+			drop.Class().Add("drag-over")
+		})
+		f.listeners.add(drop, "dragleave", func(evt dom.Event) {
+			drop.Class().Remove("drag-over")
+		})
+		f.listeners.add(drop, "drop", func(evt dom.Event) {
+			evt.PreventDefault()
+			drop.Class().Remove("drag-over")
+			fl := evt.Underlying().Get("dataTransfer").Get("files")
+			files := []dom.Value{}
+			for i := 0; i < fl.Length(); i++ {
+				files = append(files, fl.Index(i))
+			}
+			f.addFiles(field, files)
+		})
+	}
+
+	if list := f.query(`[name="` + field.Model + `List"]`); list != nil {
+		remove := func(evt dom.Event) {
+			el := evt.Target().Closest(".file-remove")
+			if el == nil {
+				return
+			}
+			evt.PreventDefault()
+			idx, err := strconv.Atoi(el.GetAttribute("idx"))
+			if err != nil || idx < 0 || idx >= len(field.Files) {
+				print("invalid file index", el.GetAttribute("idx"))
+				return
+			}
+			field.Files = append(field.Files[:idx], field.Files[idx+1:]...)
+			f.paintFiles(field)
+			f.fieldChanged(field)
+		}
+		f.listeners.add(list, "click", remove)
+		f.listeners.add(list, "keydown", func(evt dom.Event) {
+			switch evt.(*dom.KeyboardEvent).Key {
+			case "Enter", " ":
+				remove(evt)
+			}
+		})
+	}
+}
+
+// Check the MIME type and size limits, and read each file that passes
+func (f *EditForm) addFiles(field *EditField, files []dom.Value) {
+	for _, file := range files {
+		name := file.Get("name").String()
+		mimeType := file.Get("type").String()
+		size := file.Get("size").Int()
+		if field.reserveFile(name, mimeType, size) {
+			f.readFile(field, file, name, mimeType, size)
+		}
+	}
+}
+
+// Check the file against the limits of the field, and keep a place for it while it
+// is read, as the reads finish later and another drop could come in before then
+func (e *EditField) reserveFile(name string, mimeType string, size int) bool {
+	if e.MaxFiles > 0 && len(e.Files)+e.reading >= e.MaxFiles {
+		print("file rejected - already have the maximum number of files", name, e.MaxFiles)
+		return false
+	}
+	if !acceptsFile(e.Accept, name, mimeType) {
+		print("file rejected - type not accepted", name, mimeType)
+		return false
+	}
+	if e.MaxSize > 0 && size > e.MaxSize {
+		print("file rejected - too big", name, size, e.MaxSize)
+		return false
+	}
+	e.reading++
+	return true
+}
+
+// Read the file into a data URL, reporting progress as it goes
+func (f *EditForm) readFile(field *EditField, file dom.Value, name string, mimeType string, size int) {
+	reader := dom.Global().Get("FileReader").New()
+	// the reader ends with onload or onerror, so let go of the callbacks then
+	var release []func()
+	done := func() {
+		for _, r := range release {
+			r()
+		}
+	}
+	onprogress, r := dom.Func(func(evt dom.Value) {
+		if f.ReadProgressCB != nil && evt.Get("lengthComputable").Bool() {
+			f.ReadProgressCB(field.Model, name, evt.Get("loaded").Int(), evt.Get("total").Int())
+		}
+	})
+	release = append(release, r)
+	onload, r := dom.Func(func(evt dom.Value) {
+		defer done()
+		field.reading--
+		field.Files = append(field.Files, FileField{
+			Data:     reader.Get("result").String(),
+			Filename: name,
+			Type:     mimeType,
+			Size:     size,
+		})
+		if f.ReadProgressCB != nil {
+			f.ReadProgressCB(field.Model, name, size, size)
+		}
+		f.paintFiles(field)
+		f.fieldChanged(field)
+		if f.AttachCB != nil {
+			go f.AttachCB()
+		}
+	})
+	release = append(release, r)
+	onerror, r := dom.Func(func(evt dom.Value) {
+		defer done()
+		field.reading--
+		print("failed to read file", name)
+	})
+	release = append(release, r)
+	reader.Set("onprogress", onprogress)
+	reader.Set("onload", onload)
+	reader.Set("onerror", onerror)
+	reader.Call("readAsDataURL", file)
+}
+
+// Paint the list of files, with a remove button on each
+func (f *EditForm) paintFiles(field *EditField) {
+	el := f.query(`[name="` + field.Model + `List"]`)
+	if el == nil {
+		print("There is no DOM element called '", field.Model+"List' to write the files into")
+		return
+	}
+
+	html := ""
+	for i, ff := range field.Files {
+		html += "<li>"
+		if strings.HasPrefix(ff.Type, "image/") && ff.Data != "" {
+			html += fmt.Sprintf(`<img class="photothumbnail" src="%s"> `, template.HTMLEscapeString(ff.Data))
+		} else {
+			html += string(f.CurrentTheme().Icon("file")) + " "
+		}
+		html += fmt.Sprintf(`%s <span class="file-size">%s</span>`,
+			template.HTMLEscapeString(ff.Filename), fileSize(ff.Size))
+		if !field.Readonly {
+			html += fmt.Sprintf(` <span class="file-remove no-print" idx="%d" role="button" tabindex="0" aria-label="%s">%s</span>`,
+				i, template.HTMLEscapeString(T("Remove")), f.CurrentTheme().Icon("remove"))
+		}
+		html += "</li>"
+	}
+	el.SetInnerHTML(html)
+}
+
+// acceptsFile matches a file against a comma separated list of
+// MIME types (image/png), wildcards (image/*) or extensions (.pdf)
+func acceptsFile(accept string, filename string, mimeType string) bool {
+	if accept == "" {
+		return true
+	}
+	filename = strings.ToLower(filename)
+	mimeType = strings.ToLower(mimeType)
+	for _, a := range strings.Split(accept, ",") {
+		a = strings.ToLower(strings.TrimSpace(a))
+		switch {
+		case a == "":
+			continue
+		case strings.HasPrefix(a, "."):
+			if strings.HasSuffix(filename, a) {
+				return true
+			}
+		case strings.HasSuffix(a, "/*"):
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(a, "*")) {
+				return true
+			}
+		case a == mimeType:
+			return true
+		}
+	}
+	return false
+}
+
+func fileSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
+
+// Read a []FileField out of the data, copying it so that edits
+// dont touch the data until the form is bound
+func getFiles(dataField reflect.Value) []FileField {
+	files := []FileField{}
+	if dataField.Kind() != reflect.Slice {
+		return files
+	}
+	for i := 0; i < dataField.Len(); i++ {
+		if ff, ok := dataField.Index(i).Interface().(FileField); ok {
+			files = append(files, ff)
+		}
+	}
+	return files
+}
+
+func setFromFiles(target reflect.Value, files []FileField) {
+
+	k := target.Kind()
+	switch k {
+	case reflect.Slice:
+		v := reflect.ValueOf(files)
+		if !v.Type().ConvertibleTo(target.Type()) {
+			print("conversion of files to unknown slice type", target.Type().String())
+			return
+		}
+		target.Set(v.Convert(target.Type()))
+	default:
+		print("conversion of files to unknown type", k.String())
+	}
+}
@@ -0,0 +1,26 @@
+package formulate
+
+import "testing"
+
+func TestReserveFile(t *testing.T) {
+	field := &EditField{Model: "Attachments", Accept: ".pdf", MaxFiles: 2, MaxSize: 1000}
+	field.Files = []FileField{{Filename: "quote.pdf"}}
+
+	if field.reserveFile("photo.jpg", "image/jpeg", 10) {
+		t.Error("a file of the wrong type was taken")
+	}
+	if field.reserveFile("big.pdf", "application/pdf", 2000) {
+		t.Error("a file that is too big was taken")
+	}
+	// the reads finish later, so a second drop must not get past MaxFiles meanwhile
+	if !field.reserveFile("invoice.pdf", "application/pdf", 10) {
+		t.Fatal("a file within the limits was rejected")
+	}
+	if field.reserveFile("receipt.pdf", "application/pdf", 10) {
+		t.Error("a file was taken while another was being read to reach MaxFiles")
+	}
+	field.reading--
+	if !field.reserveFile("receipt.pdf", "application/pdf", 10) {
+		t.Error("a failed read kept its place")
+	}
+}
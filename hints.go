@@ -0,0 +1,112 @@
+package formulate
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Placeholder sets the example value shown in the last field added to the row, while it is empty
+func (r *EditRow) Placeholder(text string) *EditRow {
+	if f := r.last(); f != nil {
+		f.Placeholder = text
+	}
+	return r
+}
+
+// Help sets the text shown under the last field added to the row, explaining what it is for
+func (r *EditRow) Help(text string) *EditRow {
+	if f := r.last(); f != nil {
+		f.Help = text
+	}
+	return r
+}
+
+// Tooltip sets the text shown on hover or focus of an icon beside the label of
+// the last field added to the row
+func (r *EditRow) Tooltip(text string) *EditRow {
+	if f := r.last(); f != nil {
+		f.Tooltip = text
+	}
+	return r
+}
+
+func (r *EditRow) last() *EditField {
+	if len(r.Fields) == 0 {
+		print("form: there is no field on the row yet")
+		return nil
+	}
+	return r.Fields[len(r.Fields)-1]
+}
+
+// DescribedBy - the ids of the elements that describe the field to screen
+// readers, which are its error, and its help and tooltip if it has them
+func (e *EditField) DescribedBy(uid string) string {
+	ids := uid + "-" + e.Model + "-error"
+	if e.Help != "" {
+		ids += " " + uid + "-" + e.Model + "-help"
+	}
+	if e.Tooltip != "" {
+		ids += " " + uid + "-" + e.Model + "-tooltip"
+	}
+	return ids
+}
+
+// Take the placeholder, help and tooltip of each field from the struct tags of the
+// data, such as `placeholder:"e.g. 12 High St" help:"Where the work is done"`,
+// unless the field already has them
+func (f *EditForm) loadHints(data interface{}) {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return
+	}
+	for _, field := range f.allFields() {
+		if field.Model == "" {
+			continue
+		}
+		sf, ok := modelStructField(t, field.Model)
+		if !ok {
+			continue
+		}
+		if field.Placeholder == "" {
+			field.Placeholder = sf.Tag.Get("placeholder")
+		}
+		if field.Help == "" {
+			field.Help = sf.Tag.Get("help")
+		}
+		if field.Tooltip == "" {
+			field.Tooltip = sf.Tag.Get("tooltip")
+		}
+	}
+}
+
+// The struct field that a model names in the type, matched the same way as modelField
+func modelStructField(t reflect.Type, model string) (reflect.StructField, bool) {
+	var sf reflect.StructField
+	for _, name := range strings.Split(model, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return sf, false
+		}
+		found := false
+		if sf, found = t.FieldByName(name); !found {
+			for i := 0; i < t.NumField(); i++ {
+				fld := t.Field(i)
+				if fld.PkgPath != "" {
+					continue
+				}
+				tag := strings.Split(fld.Tag.Get("json"), ",")[0]
+				if tag == name || (tag == "" && strings.EqualFold(fld.Name, name)) {
+					sf, found = fld, true
+					break
+				}
+			}
+		}
+		if !found {
+			return sf, false
+		}
+		t = sf.Type
+	}
+	return sf, true
+}
@@ -0,0 +1,63 @@
+package formulate
+
+import (
+	"strings"
+	"testing"
+)
+
+type testSite struct {
+	Address string `placeholder:"12 High St" help:"Where the work is done"`
+	Contact string `tooltip:"Who to ask for on site" help:"From the tag"`
+	Visits  int
+	Notes   string
+}
+
+func TestHints(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f := &EditForm{}
+	f.New("fa-map", "Site")
+	f.Row(2).
+		AddInput(1, "Address", "Address").
+		AddInput(1, "Contact", "Contact").Help("From the builder")
+	swapper := &Swapper{Name: "More"}
+	swapper.AddPanel("Visits").AddRow(2).
+		AddNumber(1, "Visits", "Visits", "1").Placeholder("0").Tooltip("Visits so far").
+		AddTextarea(1, "Notes", "Notes").Help("Anything else")
+	f.Row(1).AddSwapper(1, "More", swapper)
+	site := &testSite{}
+	f.Render("edit-form", "#form", site)
+
+	address := fakeField(t, doc, `[name="Address"]`)
+	if address.attrs["placeholder"] != "12 High St" {
+		t.Errorf("Address placeholder = %q", address.attrs["placeholder"])
+	}
+	if got := fakeField(t, doc, "#"+f.UID+"-Address-help").textContent(); got != "Where the work is done" {
+		t.Errorf("Address help = %q", got)
+	}
+	if !strings.Contains(address.attrs["aria-describedby"], f.UID+"-Address-help") {
+		t.Errorf("Address is described by %q", address.attrs["aria-describedby"])
+	}
+
+	contact := fakeField(t, doc, `[name="Contact"]`)
+	if got := fakeField(t, doc, "#"+f.UID+"-Contact-help").textContent(); got != "From the builder" {
+		t.Errorf("the builder should win over the tag, help = %q", got)
+	}
+	tip := fakeField(t, doc, "#"+f.UID+"-Contact-tooltip")
+	if tip.attrs["title"] != "Who to ask for on site" || tip.attrs["tabindex"] != "0" {
+		t.Errorf("Contact tooltip = %v", tip.attrs)
+	}
+	if want := f.UID + "-Contact-error " + f.UID + "-Contact-help " + f.UID + "-Contact-tooltip"; contact.attrs["aria-describedby"] != want {
+		t.Errorf("Contact is described by %q", contact.attrs["aria-describedby"])
+	}
+
+	visits := fakeField(t, doc, `[name="Visits"]`)
+	if visits.attrs["placeholder"] != "0" || !strings.Contains(visits.attrs["aria-describedby"], f.UID+"-Visits-tooltip") {
+		t.Errorf("Visits = %v", visits.attrs)
+	}
+	if got := fakeField(t, doc, "#"+f.UID+"-Notes-help").textContent(); got != "Anything else" {
+		t.Errorf("Notes help = %q", got)
+	}
+	if _, ok := fakeField(t, doc, `[name="Notes"]`).attrs["placeholder"]; ok {
+		t.Error("Notes has a placeholder it was not given")
+	}
+}
@@ -0,0 +1,250 @@
+package formulate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Locale - the messages, names and formats that all forms are displayed with
+type Locale struct {
+	Name            string            // such as "en" or "fr-FR"
+	Messages        map[string]string // built in English text -> translated text
+	Months          [12]string
+	ShortMonths     [12]string
+	Days            [7]string // starting on Sunday
+	ShortDays       [7]string
+	DateLayout      string // Go layout for dates in lists and display mode
+	ShortDateLayout string // Go layout for dates on small screens
+	Decimal         string
+	Thousands       string
+	True            string
+	False           string
+}
+
+// English is the default locale
+var English = &Locale{
+	Name:     "en",
+	Messages: map[string]string{},
+	Months: [12]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"},
+	ShortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	Days:            [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	ShortDays:       [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	DateLayout:      "Mon, Jan 2 2006",
+	ShortDateLayout: "2 Jan",
+	Decimal:         ".",
+	Thousands:       ",",
+	True:            "Yes",
+	False:           "No",
+}
+
+var locale = English
+
+// SetLocale changes the locale for all forms rendered from now on.
+// Names, layouts, the decimal point and yes/no that are left empty are taken from English
+func SetLocale(l *Locale) {
+	if l == nil {
+		locale = English
+		return
+	}
+	if l.Messages == nil {
+		l.Messages = map[string]string{}
+	}
+	if l.Months[0] == "" {
+		l.Months = English.Months
+	}
+	if l.ShortMonths[0] == "" {
+		l.ShortMonths = English.ShortMonths
+	}
+	if l.Days[0] == "" {
+		l.Days = English.Days
+	}
+	if l.ShortDays[0] == "" {
+		l.ShortDays = English.ShortDays
+	}
+	if l.DateLayout == "" {
+		l.DateLayout = English.DateLayout
+	}
+	if l.ShortDateLayout == "" {
+		l.ShortDateLayout = English.ShortDateLayout
+	}
+	if l.Decimal == "" {
+		l.Decimal = English.Decimal
+	}
+	if l.True == "" {
+		l.True = English.True
+	}
+	if l.False == "" {
+		l.False = English.False
+	}
+	locale = l
+}
+
+// CurrentLocale returns the locale that forms are displayed with
+func CurrentLocale() *Locale {
+	return locale
+}
+
+// T translates a built in message, returning it unchanged if there is no translation
+func T(msg string) string {
+	return locale.T(msg)
+}
+
+// T translates a message, returning it unchanged if there is no translation
+func (l *Locale) T(msg string) string {
+	if s, ok := l.Messages[msg]; ok && s != "" {
+		return s
+	}
+	return msg
+}
+
+// The names in a Go layout, longest first so that "Monday" is not read as "Mon"
+var layoutNames = []string{"Monday", "January", "Mon", "Jan"}
+
+// FormatDate formats the time with a Go layout, using the locale's month and day names
+func (l *Locale) FormatDate(t time.Time, layout string) string {
+	s := ""
+	for layout != "" {
+		i, name := nextLayoutName(layout)
+		if i < 0 {
+			s += t.Format(layout)
+			break
+		}
+		if i > 0 {
+			s += t.Format(layout[:i])
+		}
+		switch name {
+		case "Monday":
+			s += l.Days[t.Weekday()]
+		case "Mon":
+			s += l.ShortDays[t.Weekday()]
+		case "January":
+			s += l.Months[t.Month()-1]
+		case "Jan":
+			s += l.ShortMonths[t.Month()-1]
+		}
+		layout = layout[i+len(name):]
+	}
+	return s
+}
+
+func nextLayoutName(layout string) (int, string) {
+	at, found := -1, ""
+	for _, name := range layoutNames {
+		if i := strings.Index(layout, name); i >= 0 && (at < 0 || i < at || (i == at && len(name) > len(found))) {
+			at, found = i, name
+		}
+	}
+	return at, found
+}
+
+// FormatNumber formats the number with the locale's separators
+func (l *Locale) FormatNumber(v float64, decimals int) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+	s := fmt.Sprintf("%.*f", decimals, math.Abs(v))
+	whole, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if l.Thousands != "" {
+		for i := len(whole) - 3; i > 0; i -= 3 {
+			whole = whole[:i] + l.Thousands + whole[i:]
+		}
+	}
+	if frac != "" {
+		whole += l.Decimal + frac
+	}
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		whole = "-" + whole
+	}
+	return whole
+}
+
+// FormatBool returns the locale's words for true and false
+func (l *Locale) FormatBool(v bool) string {
+	if v {
+		return l.True
+	}
+	return l.False
+}
+
+// Get the time out of a time.Time or *time.Time
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, !t.IsZero()
+	}
+	return time.Time{}, false
+}
+
+// The text to show for a field in display mode
+func displayValue(field *EditField, v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		decimals := field.Decimals
+		if !field.IsFloat && decimals == 0 {
+			decimals = 2
+		}
+		return locale.FormatNumber(v.Float(), decimals)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return locale.FormatNumber(float64(v.Int()), 0)
+	case reflect.Bool:
+		return locale.FormatBool(v.Bool())
+	case reflect.Struct:
+		if t, ok := toTime(v.Interface()); ok {
+			return locale.FormatDate(t, locale.DateLayout)
+		}
+		return ""
+	}
+	return field.Value
+}
+
+// Template funcs for the generated list and tree templates, so that
+// they pick up the locale when they are executed, rather than when generated
+func localeFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"t": T,
+		"date": func(v interface{}) string {
+			if t, ok := toTime(v); ok {
+				return locale.FormatDate(t, locale.DateLayout)
+			}
+			return ""
+		},
+		"shortDate": func(v interface{}) string {
+			if t, ok := toTime(v); ok {
+				return locale.FormatDate(t, locale.ShortDateLayout)
+			}
+			return ""
+		},
+		"yesno": func(v bool) string {
+			return locale.FormatBool(v)
+		},
+	}
+}
+
+// T translates a built in message for the edit form template
+func (f *EditForm) T(msg string) string {
+	return T(msg)
+}
+
+// T translates a built in message for the list form template
+func (f *ListForm) T(msg string) string {
+	return T(msg)
+}
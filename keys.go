@@ -0,0 +1,320 @@
+package formulate
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Shortcut - a keyboard binding on a form
+type Shortcut struct {
+	Key         string // KeyboardEvent.key, or a single letter or digit
+	Ctrl        bool   // Ctrl, or Cmd on a Mac
+	Alt         bool
+	Shift       bool
+	Description string
+	Action      func(dom.Event)
+}
+
+// The form that gets the keyboard shortcuts, by UID.
+// This is the last form to be rendered, focused or clicked on
+var activeForm string
+
+// NewShortcut parses keys such as "Ctrl+S", "Alt+N" or "Escape" into a Shortcut
+func NewShortcut(keys string, description string, action func(dom.Event)) *Shortcut {
+	s := &Shortcut{
+		Description: description,
+		Action:      action,
+	}
+	parts := strings.Split(keys, "+")
+	// allow for "Ctrl++"
+	if strings.HasSuffix(keys, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	for i, p := range parts {
+		if i == len(parts)-1 {
+			s.Key = p
+			break
+		}
+		switch strings.ToLower(p) {
+		case "ctrl", "cmd", "meta":
+			s.Ctrl = true
+		case "alt", "option":
+			s.Alt = true
+		case "shift":
+			s.Shift = true
+		default:
+			print("unknown modifier in shortcut", keys)
+		}
+	}
+	return s
+}
+
+// String shows the shortcut in the same form that NewShortcut reads
+func (s *Shortcut) String() string {
+	keys := ""
+	if s.Ctrl {
+		keys += "Ctrl+"
+	}
+	if s.Alt {
+		keys += "Alt+"
+	}
+	if s.Shift {
+		keys += "Shift+"
+	}
+	return keys + s.Key
+}
+
+// Single letters and digits are matched on the physical key, so that
+// Alt+N still works on a Mac where Alt changes the character typed
+func (s *Shortcut) isCharacter() bool {
+	r := []rune(s.Key)
+	return len(r) == 1
+}
+
+func (s *Shortcut) matches(key string, code string, ctrl, alt, shift bool) bool {
+	if s.Ctrl != ctrl || s.Alt != alt {
+		return false
+	}
+	if s.isCharacter() {
+		r := []rune(s.Key)[0]
+		switch {
+		case unicode.IsLetter(r):
+			return s.Shift == shift && code == "Key"+strings.ToUpper(s.Key)
+		case unicode.IsDigit(r):
+			return s.Shift == shift && code == "Digit"+s.Key
+		}
+		// punctuation, where shift is part of the character typed
+		return key == s.Key
+	}
+	return s.Shift == shift && strings.EqualFold(key, s.Key)
+}
+
+// Plain characters are left alone while the user is typing into a field
+func isTyping(el dom.Element) bool {
+	if el == nil {
+		return false
+	}
+	switch el.TagName() {
+	case "INPUT", "TEXTAREA", "SELECT":
+		return true
+	}
+	return el.GetAttribute("contenteditable") == "true"
+}
+
+// Listen for the shortcuts on the document, acting on them only while the form is active
+func listenKeys(l *listeners, root dom.Element, t Theme, uid string, keys []*Shortcut, escape func() bool) {
+	doc := dom.GetWindow().Document()
+
+	activeForm = uid
+	if root != nil {
+		makeActive := func(evt dom.Event) {
+			activeForm = uid
+		}
+		l.add(root, "focusin", makeActive)
+		l.add(root, "pointerdown", makeActive)
+	}
+
+	keys = append(keys, NewShortcut("?", "Show the keyboard shortcuts", func(evt dom.Event) {
+		showShortcuts(root, t, keys)
+	}))
+
+	l.add(doc, "keydown", func(evt dom.Event) {
+		if activeForm != uid {
+			return
+		}
+		kevt, ok := evt.(*dom.KeyboardEvent)
+		if !ok || kevt == nil {
+			return
+		}
+		key := kevt.Key
+		code := evt.Underlying().Get("code").String()
+		ctrl := kevt.CtrlKey || kevt.MetaKey
+
+		// Escape closes the help and any modals before it cancels the form
+		if key == "Escape" && !ctrl && !kevt.AltKey {
+			if root != nil {
+				if el := root.QuerySelector(".shortcut-help"); el != nil {
+					evt.PreventDefault()
+					el.ParentNode().RemoveChild(el)
+					return
+				}
+			}
+			if escape != nil && escape() {
+				evt.PreventDefault()
+				return
+			}
+		}
+
+		s := shortcutFor(keys, key, code, ctrl, kevt.AltKey, kevt.ShiftKey, isTyping(evt.Target()))
+		if s == nil {
+			return
+		}
+		evt.PreventDefault()
+		if s.Action != nil {
+			s.Action(evt)
+		}
+	})
+}
+
+// The shortcut for a key press, or nil if there is none or it is a plain character
+// typed into a field
+func shortcutFor(keys []*Shortcut, key string, code string, ctrl, alt, shift, typing bool) *Shortcut {
+	for _, s := range keys {
+		if !s.matches(key, code, ctrl, alt, shift) {
+			continue
+		}
+		if !s.Ctrl && !s.Alt && s.isCharacter() && typing {
+			return nil
+		}
+		return s
+	}
+	return nil
+}
+
+// The form is going away, so it no longer gets the keyboard shortcuts
+func releaseKeys(uid string) {
+	if uid != "" && activeForm == uid {
+		activeForm = ""
+	}
+}
+
+// Toggle an overlay listing the shortcuts
+func showShortcuts(root dom.Element, t Theme, keys []*Shortcut) {
+	if root == nil {
+		return
+	}
+	if el := root.QuerySelector(".shortcut-help"); el != nil {
+		el.ParentNode().RemoveChild(el)
+		return
+	}
+
+	html := fmt.Sprintf(`<div class="%s"><h3>%s</h3><table class="%s">`,
+		t.Class("modal-content"), template.HTMLEscapeString(T("Keyboard Shortcuts")), t.Class("table"))
+	for _, s := range keys {
+		html += fmt.Sprintf("<tr><td><kbd>%s</kbd></td><td>%s</td></tr>",
+			template.HTMLEscapeString(s.String()), template.HTMLEscapeString(T(s.Description)))
+	}
+	html += `</table></div>`
+
+	div := dom.GetWindow().Document().CreateElement("div")
+	div.Class().SetString("shortcut-help " + t.Class("modal") + " " + t.Class("modal-show"))
+	div.SetInnerHTML(html)
+	root.AppendChild(div)
+}
+
+// Add a keyboard shortcut to the editform, such as "Ctrl+P" or "Alt+1"
+func (f *EditForm) AddShortcut(keys string, description string, c func(dom.Event)) *EditForm {
+	f.Shortcuts = append(f.Shortcuts, NewShortcut(keys, description, c))
+	return f
+}
+
+// Show or hide the list of keyboard shortcuts for the editform
+func (f *EditForm) ShowShortcuts() {
+	showShortcuts(f.Root, f.CurrentTheme(), f.keys())
+}
+
+// The built in shortcuts for the editform's callbacks, then the ones the app added
+func (f *EditForm) keys() []*Shortcut {
+	keys := []*Shortcut{}
+	if f.CancelCB != nil {
+		keys = append(keys, NewShortcut("Escape", "Cancel", f.CancelCB))
+	}
+	if f.SaveCB != nil {
+		keys = append(keys, NewShortcut("Ctrl+S", "Save", f.SaveCB))
+	}
+	if f.DeleteCB != nil {
+		keys = append(keys, NewShortcut("Ctrl+Delete", "Delete this record", func(evt dom.Event) {
+			f.showDelete(true)
+		}))
+	}
+	return append(keys, f.Shortcuts...)
+}
+
+func (f *EditForm) decorateKeys() {
+	listenKeys(&f.listeners, f.Root, f.CurrentTheme(), f.UID, f.keys(), func() bool {
+		if el := f.query(f.id("confirm-delete")); el != nil && hasClass(el, f.CurrentTheme().Class("modal-show")) {
+			f.showDelete(false)
+			return true
+		}
+		return false
+	})
+}
+
+// Show or hide the delete confirmation
+func (f *EditForm) showDelete(show bool) {
+	el := f.query(f.id("confirm-delete"))
+	if el == nil {
+		return
+	}
+	doc := dom.GetWindow().Document().(dom.HTMLDocument)
+	if show {
+		// remember where focus was, to put it back when the dialog closes
+		f.lastFocus = doc.ActiveElement()
+		setClass(el, f.CurrentTheme().Class("modal-show"), true)
+		if btn := el.QuerySelector(".md-close-del"); btn != nil {
+			btn.(dom.HTMLElement).Focus()
+		}
+	} else {
+		setClass(el, f.CurrentTheme().Class("modal-show"), false)
+		if f.lastFocus != nil {
+			f.lastFocus.Focus()
+			f.lastFocus = nil
+		}
+	}
+}
+
+// Add a keyboard shortcut to the listform, such as "Ctrl+P" or "Alt+1"
+func (f *ListForm) AddShortcut(keys string, description string, c func(dom.Event)) *ListForm {
+	f.Shortcuts = append(f.Shortcuts, NewShortcut(keys, description, c))
+	return f
+}
+
+// Show or hide the list of keyboard shortcuts for the listform
+func (f *ListForm) ShowShortcuts() {
+	showShortcuts(f.Root, f.CurrentTheme(), f.keys())
+}
+
+func (f *ListForm) keys() []*Shortcut {
+	keys := []*Shortcut{}
+	if f.CancelCB != nil {
+		keys = append(keys, NewShortcut("Escape", "Close", f.CancelCB))
+	}
+	if f.NewRowCB != nil {
+		keys = append(keys, NewShortcut("Alt+N", "Add a new row", f.NewRowCB))
+	}
+	return append(keys, f.Shortcuts...)
+}
+
+func (f *ListForm) decorateKeys() {
+	listenKeys(&f.listeners, f.Root, f.CurrentTheme(), f.UID, f.keys(), nil)
+}
+
+// Add a keyboard shortcut to the treeform, such as "Ctrl+P" or "Alt+1"
+func (f *TreeForm) AddShortcut(keys string, description string, c func(dom.Event)) *TreeForm {
+	f.Shortcuts = append(f.Shortcuts, NewShortcut(keys, description, c))
+	return f
+}
+
+// Show or hide the list of keyboard shortcuts for the treeform
+func (f *TreeForm) ShowShortcuts() {
+	showShortcuts(f.Root, f.CurrentTheme(), f.keys())
+}
+
+func (f *TreeForm) keys() []*Shortcut {
+	keys := []*Shortcut{}
+	if f.CancelCB != nil {
+		keys = append(keys, NewShortcut("Escape", "Close", f.CancelCB))
+	}
+	if f.NewRowCB != nil {
+		keys = append(keys, NewShortcut("Alt+N", "Add a new row", f.NewRowCB))
+	}
+	return append(keys, f.Shortcuts...)
+}
+
+func (f *TreeForm) decorateKeys() {
+	listenKeys(&f.listeners, f.Root, f.CurrentTheme(), f.UID, f.keys(), nil)
+}
@@ -0,0 +1,77 @@
+package formulate
+
+import (
+	"testing"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+func TestShortcutDispatch(t *testing.T) {
+	var ran []string
+	action := func(name string) func(dom.Event) {
+		return func(dom.Event) { ran = append(ran, name) }
+	}
+	keys := []*Shortcut{
+		NewShortcut("Escape", "Cancel", action("cancel")),
+		NewShortcut("Ctrl+S", "Save", action("save")),
+		NewShortcut("Alt+N", "New", action("new")),
+		NewShortcut("?", "Help", action("help")),
+		NewShortcut("n", "Next", action("next")),
+	}
+	if s := keys[1].String(); s != "Ctrl+S" {
+		t.Errorf("String() = %q", s)
+	}
+
+	cases := []struct {
+		key, code        string
+		ctrl, alt, shift bool
+		typing           bool
+		want             string
+	}{
+		{"s", "KeyS", true, false, false, true, "save"},
+		{"S", "KeyS", true, false, true, false, ""},
+		// Alt changes the character typed on a Mac, so the physical key is matched
+		{"˜", "KeyN", false, true, false, false, "new"},
+		{"Escape", "Escape", false, false, false, true, "cancel"},
+		{"?", "Slash", false, false, true, false, "help"},
+		{"n", "KeyN", false, false, false, false, "next"},
+		// plain characters are left to the field being typed into
+		{"n", "KeyN", false, false, false, true, ""},
+		{"?", "Slash", false, false, true, true, ""},
+		{"x", "KeyX", true, false, false, false, ""},
+	}
+	for _, c := range cases {
+		ran = nil
+		s := shortcutFor(keys, c.key, c.code, c.ctrl, c.alt, c.shift, c.typing)
+		if s != nil && s.Action != nil {
+			s.Action(nil)
+		}
+		got := ""
+		if len(ran) > 0 {
+			got = ran[0]
+		}
+		if got != c.want {
+			t.Errorf("%q (%s ctrl=%v alt=%v shift=%v typing=%v) ran %q, want %q",
+				c.key, c.code, c.ctrl, c.alt, c.shift, c.typing, got, c.want)
+		}
+	}
+}
+
+func TestDestroyReleasesKeys(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+	other := &EditForm{}
+	other.UID = "formulate-other"
+
+	activeForm = f.UID
+	other.Destroy()
+	if activeForm != f.UID {
+		t.Errorf("destroying another form took the shortcuts from %q", f.UID)
+	}
+	f.Destroy()
+	if activeForm != "" {
+		t.Errorf("activeForm = %q after Destroy", activeForm)
+	}
+}
@@ -0,0 +1,122 @@
+package formulate
+
+import (
+	"github.com/steveoc64/formulate/dom"
+)
+
+// listeners keeps track of every event listener a form adds,
+// so that they can all be removed again
+type listeners []func()
+
+func (l *listeners) add(target dom.EventTarget, event string, cb func(dom.Event)) {
+	fn := target.AddEventListener(event, false, cb)
+	*l = append(*l, func() {
+		target.RemoveEventListener(event, false, fn)
+	})
+}
+
+// Listen on a node, which may be a fake one in tests
+func (l *listeners) addNode(n node, event string, cb func(dom.Event)) {
+	*l = append(*l, n.AddEventListener(event, cb))
+}
+
+func (l *listeners) removeAll() {
+	for _, remove := range *l {
+		remove()
+	}
+	*l = nil
+}
+
+// Destroy removes all the event listeners and markup that the form added,
+// and lets go of the data that it was rendered with
+func (f *EditForm) Destroy() {
+	f.listeners.removeAll()
+	releaseKeys(f.UID)
+	// the delete modal may have been moved out of the form, so remove it directly
+	if f.Root != nil {
+		if el := f.query(f.id("confirm-delete")); el != nil {
+			el.ParentNode().RemoveChild(el)
+		}
+	}
+	if f.root != nil {
+		f.root.SetInnerHTML("")
+	}
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			field.Files = nil
+			if field.Swapper != nil {
+				field.Swapper.Root = nil
+				field.Swapper.root = nil
+				for _, p := range field.Swapper.Panels {
+					p.Root = nil
+					p.root = nil
+					p.form = nil
+				}
+			}
+		}
+	}
+	f.Root = nil
+	f.root = nil
+	f.rerender = nil
+	f.repaint = nil
+	f.data = nil
+	f.dataType = nil
+	f.original = nil
+	f.conflict = nil
+	f.watched = nil
+	f.lastFocus = nil
+	f.IsRendered = false
+}
+
+// Rerender the form with the same template, selector and data as the last Render
+func (f *EditForm) Rerender() {
+	if f.rerender == nil {
+		print("ERROR: Rerender() called before render, or after destroy")
+		return
+	}
+	f.rerender()
+}
+
+// Destroy removes all the event listeners and markup that the form added,
+// and lets go of the data that it was rendered with
+func (f *ListForm) Destroy() {
+	f.listeners.removeAll()
+	releaseKeys(f.UID)
+	if f.Root != nil {
+		f.Root.SetInnerHTML("")
+	}
+	f.Root = nil
+	f.Data = nil
+	f.rerender = nil
+}
+
+// Rerender the form the same way as the last Render
+func (f *ListForm) Rerender() {
+	if f.rerender == nil {
+		print("ERROR: Rerender() called before render, or after destroy")
+		return
+	}
+	f.rerender()
+}
+
+// Destroy removes all the event listeners and markup that the form added,
+// and lets go of the data that it was rendered with
+func (f *TreeForm) Destroy() {
+	f.listeners.removeAll()
+	releaseKeys(f.UID)
+	if f.Root != nil {
+		f.Root.SetInnerHTML("")
+	}
+	f.Root = nil
+	f.Data = nil
+	f.rerender = nil
+}
+
+// Rerender the form the same way as the last Render
+func (f *TreeForm) Rerender() {
+	if f.rerender == nil {
+		print("ERROR: Rerender() called before render, or after destroy")
+		return
+	}
+	f.rerender()
+}
@@ -0,0 +1,29 @@
+package formulate
+
+import "testing"
+
+func TestDestroyReleasesData(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1}
+	f := newTestForm(job)
+	f.OnFieldChange("Name", func(old, new interface{}) {})
+	f.Render("edit-form", "#form", job)
+	f.SetValue("Name", "Pump rebuild")
+	if f.data == nil || f.original == nil || f.watched == nil || f.repaint == nil {
+		t.Fatal("the form did not keep its data while rendered")
+	}
+
+	f.Destroy()
+	if f.data != nil || f.dataType != nil || f.original != nil || f.conflict != nil ||
+		f.watched != nil || f.repaint != nil || f.rerender != nil || f.lastFocus != nil {
+		t.Errorf("the destroyed form still holds its data: %+v", f)
+	}
+	if f.root != nil || f.IsRendered {
+		t.Error("the destroyed form is still attached")
+	}
+	for _, p := range f.GetField("").Swapper.Panels {
+		if p.form != nil || p.root != nil {
+			t.Errorf("panel %s still holds the form", p.Name)
+		}
+	}
+}
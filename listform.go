@@ -5,7 +5,7 @@ import (
 
 	"github.com/go-humble/temple/temple"
 
-	"honnef.co/go/js/dom"
+	"github.com/steveoc64/formulate/dom"
 )
 
 type ListCol struct {
@@ -20,6 +20,8 @@ type ListCol struct {
 	MaxChars  int
 	IsIcon    bool
 	CanEdit   bool
+	HideAt    []string          // the breakpoints that the column is hidden at
+	Formats   map[string]string // the format at each breakpoint that differs from Format
 }
 
 type ListForm struct {
@@ -37,6 +39,15 @@ type ListForm struct {
 	Draggable   bool
 	HasImages   bool
 	MaxChars    int
+	Policy      *Policy
+	Shortcuts   []*Shortcut
+	UID         string
+	Theme       Theme
+	Root        dom.Element
+	listeners   listeners
+	rerender    func()
+	breakpoint  string
+	events      map[string]string // callback names, by event
 }
 
 // Init a new listform
@@ -208,6 +219,10 @@ func (f *ListForm) Render(name string, selector string, data interface{}) {
 	if dom.GetWindow().Document().QuerySelector(selector) == nil {
 		return
 	}
+	f.attach(selector)
+	f.rerender = func() {
+		f.Render(name, selector, data)
+	}
 	// print("loading into selector", selector)
 	renderTemplateT(f.generateTemplate(name, true), selector, f)
 	f.decorate(selector)
@@ -218,6 +233,10 @@ func (f *ListForm) RenderNoContainer(name string, selector string, data interfac
 	if dom.GetWindow().Document().QuerySelector(selector) == nil {
 		return
 	}
+	f.attach(selector)
+	f.rerender = func() {
+		f.RenderNoContainer(name, selector, data)
+	}
 	renderTemplateT(f.generateTemplate(name, false), selector, f)
 	f.decorate(selector)
 }
@@ -226,49 +245,55 @@ func (f *ListForm) RenderNoContainer(name string, selector string, data interfac
 func (f *ListForm) RenderCustom(name string, selector string, data interface{}) {
 
 	f.Data = data
+	f.attach(selector)
+	f.rerender = func() {
+		f.RenderCustom(name, selector, data)
+	}
 	renderTemplate(name, selector, data)
 	f.decorate(selector)
 }
 
 func (f *ListForm) decorate(selector string) {
 
-	w := dom.GetWindow()
-	doc := w.Document()
+	// drop the listeners from any previous render, so they dont stack up
+	f.listeners.removeAll()
+	if f.Root == nil {
+		return
+	}
 
 	// If there is a focusfield, then focus on it
-	if el := doc.QuerySelector("#focusme"); el != nil {
+	if el := f.query(f.id("focusme")); el != nil {
 		el.(*dom.HTMLInputElement).Focus()
 	}
 
 	// plug in cancel callbacks
 	if f.CancelCB != nil {
-		if el := doc.QuerySelector("#legend"); el != nil {
-			el.AddEventListener("click", false, f.CancelCB)
+		if el := f.query(f.id("legend")); el != nil {
+			f.listeners.add(el, "click", f.CancelCB)
 		}
 
-		if el := doc.QuerySelector(".md-close"); el != nil {
-			el.AddEventListener("click", false, f.CancelCB)
+		if el := f.query(".md-close"); el != nil {
+			f.listeners.add(el, "click", f.CancelCB)
 		}
 	}
 
 	if f.NewRowCB != nil {
-		if el := doc.QuerySelector(".data-add-btn"); el != nil {
-			el.AddEventListener("click", false, f.NewRowCB)
+		if el := f.query(".data-add-btn"); el != nil {
+			f.listeners.add(el, "click", f.NewRowCB)
 		}
 	}
 
 	if f.PrintCB != nil {
-		if el := doc.QuerySelector(".data-print-btn"); el != nil {
-			el.AddEventListener("click", false, f.PrintCB)
+		if el := f.query(".data-print-btn"); el != nil {
+			f.listeners.add(el, "click", f.PrintCB)
 		}
 	}
 
-	// Handlers on the table itself
-	sel := doc.QuerySelector(selector)
-	if el := sel.QuerySelector(".data-table"); el != nil {
+	// Handlers on the table itself, found by its id as the theme sets its class
+	if el := f.query(f.id("list-form")); el != nil {
 
 		if f.RowCB != nil {
-			el.AddEventListener("click", false, func(evt dom.Event) {
+			f.listeners.add(el, "click", func(evt dom.Event) {
 				evt.PreventDefault()
 				// Fix the issue where the user clicked on some clickable element inside the row
 				// which adds an extra level which we dont usually need
@@ -283,21 +308,24 @@ func (f *ListForm) decorate(selector string) {
 				}
 			})
 		}
-
-		if f.CancelCB != nil {
-			el.AddEventListener("keyup", false, func(evt dom.Event) {
-				if evt.(*dom.KeyboardEvent).KeyCode == 27 {
-					evt.PreventDefault()
-					el.AddEventListener("click", false, f.CancelCB)
-				}
-			})
-		}
 	}
+
+	// lay the list out again when the window changes to another breakpoint
+	f.decorateResize()
+
+	// plug in the keyboard shortcuts
+	f.decorateKeys()
+	activateButtons(&f.listeners, f.Root)
 }
 
 func (f *ListForm) generateTemplate(name string, container bool) *temple.Template {
 
-	isMobile := dom.GetWindow().InnerWidth() < 740
+	if generatedTemplates == nil {
+		print("ERROR: no templates, call Templates() first")
+		return nil
+	}
+	f.breakpoint = CurrentBreakpoint()
+	name = f.templateName(name)
 	//print("looking for template", name)
 	tmpl, err := generatedTemplates.GetTemplate(name)
 	if err != nil {
@@ -316,34 +344,34 @@ func (f *ListForm) generateTemplate(name string, container bool) *temple.Templat
 			if container {
 
 				src += `
-<div class="data-container">
-	<div class="row data-table-header">
-    <h3 class="column column-90" id="legend">
-      <i class="fa {{.Icon}} fa-lg" style="font-size: 3rem"></i> 
+<div class="{{.CurrentTheme.Class "container"}}">
+	<div class="{{.CurrentTheme.Class "header"}}">
+    <h3 class="{{.CurrentTheme.Class "title"}} legend" id="{{.UID}}-legend">
+      {{.CurrentTheme.TitleIcon .Icon}}
       {{.Title}}
     </h3>
 `
 			} else {
 				src += `
-	<div class="row data-table-header">
-    <h3 class="column column-90" id="legend">
-      <i class="fa {{.Icon}} fa-lg" style="font-size: 3rem"></i> 
+	<div class="{{.CurrentTheme.Class "header"}}">
+    <h3 class="{{.CurrentTheme.Class "title"}} legend" id="{{.UID}}-legend">
+      {{.CurrentTheme.TitleIcon .Icon}}
       {{.Title}}
     </h3>
 `
 			}
 			if f.NewRowCB != nil {
 				src += `
-    <div class="column col-center">
-      <i class="data-add-btn fa fa-plus-circle fa-lg no-print"></i>    
+    <div class="{{.CurrentTheme.Class "header-button"}}">
+      <span class="data-add-btn no-print" role="button" tabindex="0" aria-label="{{t "Add"}}">{{.CurrentTheme.Icon "add"}}</span>
     </div>    
 `
 			}
 
 			if f.PrintCB != nil {
 				src += `
-    <div class="column col-center">
-      <i class="data-print-btn fa fa-print fa-lg no-print"></i>    
+    <div class="{{.CurrentTheme.Class "header-button"}}">
+      <span class="data-print-btn no-print" role="button" tabindex="0" aria-label="{{t "Print"}}">{{.CurrentTheme.Icon "print"}}</span>
     </div>    
 `
 			}
@@ -352,17 +380,17 @@ func (f *ListForm) generateTemplate(name string, container bool) *temple.Templat
 `
 		}
 
-		src += `<table class="data-table" id="list-form">
+		src += `<table class="{{.CurrentTheme.Class "table"}}" id="{{.UID}}-list-form" data-formulate="{{.UID}}">
   <thead>
     <tr>
-      {{range .Cols}}
+      {{range .VisibleCols}}
       <th>{{.Heading}}</th>
       {{end}}
     </tr>
   </thead>
   <tbody>
   
-{{$cols := .Cols}}
+{{$cols := .VisibleCols}}
 {{range .Data}}  
     <tr class="data-row`
 
@@ -376,15 +404,16 @@ func (f *ListForm) generateTemplate(name string, container bool) *temple.Templat
 
 		// for each column, add a column renderer
 
-		for _, col := range f.Cols {
+		for _, col := range f.VisibleCols() {
 			width := ""
 			if f.HasSetWidth {
 				width = fmt.Sprintf(` width="%s"`, col.Width)
 			}
+			format := col.formatAt(f.breakpoint)
 
 			if col.IsImg {
 				if col.IsArray { // MultiImgColunn
-					src += fmt.Sprintf("<td %s %s>{{range $k,$v := .%s}}", width, col.Format, col.Model)
+					src += fmt.Sprintf("<td %s %s>{{range $k,$v := .%s}}", width, format, col.Model)
 
 					src += fmt.Sprintf("{{if $v.%s}}<img name=%s-{{$k}}-{{.ID}} src={{$v.%s | safeURL}}>{{end}}",
 						col.Fieldname, col.Model, col.Fieldname)
@@ -395,33 +424,30 @@ func (f *ListForm) generateTemplate(name string, container bool) *temple.Templat
 						width, col.Model, col.Model, col.Model)
 				}
 			} else if col.IsBool {
-				src += fmt.Sprintf("<td %s %s>{{if .%s}}<i class=\"fa fa-check fa-lg\">{{end}}</td>\n",
-					width, col.Format, col.Model)
+				src += fmt.Sprintf("<td %s %s>{{if .%s}}<span title=\"{{yesno true}}\">{{$.CurrentTheme.Icon \"check\"}}</span>{{end}}</td>\n",
+					width, format, col.Model)
 			} else if col.IsIcon {
 				src += fmt.Sprintf("<td %s %s><i class=\"{{.%s}}\"></td>\n",
-					width, col.Format, col.Model)
-			} else if col.Format == "date" {
-				if isMobile {
-					print("is a date col on mobile layout")
-					src += fmt.Sprintf("<td %s>{{if .%s}}{{.%s.Format \"2 Jan\"}}{{end}}</td>\n",
-						width, col.Model, col.Model)
-				} else {
-					src += fmt.Sprintf("<td %s>{{if .%s}}{{.%s.Format \"Mon, Jan 2 2006\"}}{{end}}</td>\n",
-						width, col.Model, col.Model)
-				}
-			} else if col.Format == "avatar" {
+					width, format, col.Model)
+			} else if format == "short-date" {
+				src += fmt.Sprintf("<td %s>{{if .%s}}{{shortDate .%s}}{{end}}</td>\n",
+					width, col.Model, col.Model)
+			} else if format == "date" {
+				src += fmt.Sprintf("<td %s>{{if .%s}}{{date .%s}}{{end}}</td>\n",
+					width, col.Model, col.Model)
+			} else if format == "avatar" {
 				src += fmt.Sprintf("<td %s>{{if .%s}}<img src=\"{{.GetAvatar 64}}\">{{end}}</td>\n",
 					width, col.Model)
-			} else if col.Format == "email-avatar" {
+			} else if format == "email-avatar" {
 				src += fmt.Sprintf("<td %s>{{if .%s}}<img src=\"{{.GetAvatar 40}}\"> {{.%s}}{{end}}</td>\n",
 					width, col.Model, col.Model)
 			} else if col.CanEdit {
 				src += fmt.Sprintf("<td %s>{{if .%s}}<input type=\"text\" value=\"{{.%s}}\">{{end}}</td>",
 					width, col.Model, col.Model)
 			} else {
-				if col.Format != "" {
+				if format != "" {
 					src += fmt.Sprintf("<td class=\"{{.%s}}\">{{if .%s}}{{.%s}}{{end}}</td>\n",
-						col.Format, col.Model, col.Model)
+						format, col.Model, col.Model)
 				} else {
 					src += fmt.Sprintf("<td %s>{{if .%s}}{{.%s}}{{end}}</td>\n",
 						width, col.Model, col.Model)
@@ -437,7 +463,7 @@ func (f *ListForm) generateTemplate(name string, container bool) *temple.Templat
   <tbody>
   </tbody>
 </table>
-<div id="action-grid" class="no-print hidden"></div>
+<div id="{{.UID}}-action-grid" class="action-grid no-print {{.CurrentTheme.Class "hidden"}}"></div>
 `
 		} else {
 			src += `      
@@ -469,9 +495,15 @@ func (f *ListForm) generateTemplate(name string, container bool) *temple.Templat
 
 }
 
-// Add actions
+// Add actions. The selector is looked up inside the form first, then in the document.
+// The old "#action-grid" selector finds the grid of this form
 func (f *ListForm) ActionGrid(template string, selector string, id interface{}, cb func(string)) {
-	ActionGrid(template, selector, id, cb)
+	selector = scopedSelector(f.UID, selector)
+	if f.query(selector) == nil {
+		ActionGrid(template, selector, id, cb)
+		return
+	}
+	actionGrid(f.Root, &f.listeners, f.CurrentTheme(), template, selector, id, cb)
 }
 
 func (f *ListForm) OldActionGrid(template string, selector string, id interface{}, cb func(string)) {
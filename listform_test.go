@@ -0,0 +1,42 @@
+package formulate
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testBulmaTheme struct {
+	DefaultTheme
+}
+
+func (testBulmaTheme) Class(part string) string {
+	if part == "table" {
+		return "table is-striped"
+	}
+	return DefaultTheme{}.Class(part)
+}
+
+func TestListFormTableUnderTheme(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="list"></div>`)
+	f := &ListForm{}
+	f.New("fa-list", "Jobs").Column("Name", "Name")
+	f.SetTheme(testBulmaTheme{})
+	f.UID = newUID()
+	f.Data = []testListRow{{ID: 1, Name: "Pump service"}}
+
+	tmpl, err := gt("list-form")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	fakeField(t, doc, "#list").SetInnerHTML(buf.String())
+
+	// the row clicks are wired to the table found by its id, whatever its class
+	table := fakeField(t, doc, f.id("list-form"))
+	if !table.HasClass("is-striped") {
+		t.Errorf("the table does not have the theme's class: %v", table.attrs)
+	}
+}
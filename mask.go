@@ -0,0 +1,278 @@
+package formulate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// A mask is a pattern that text is typed into, such as "(99) 9999 9999" or "AAA-9999".
+// In a mask, 9 takes a digit, A takes a letter, and * takes a letter or a digit.
+// Anything else is put in as it is, and \ puts in the next character as it is
+type mask []maskSlot
+
+type maskSlot struct {
+	literal bool
+	ch      rune
+}
+
+func parseMask(pattern string) mask {
+	m := mask{}
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			m = append(m, maskSlot{literal: true, ch: c})
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '9' || c == 'A' || c == '*':
+			m = append(m, maskSlot{ch: c})
+		default:
+			m = append(m, maskSlot{literal: true, ch: c})
+		}
+	}
+	return m
+}
+
+// Whether the slot takes the character that has been typed
+func (s maskSlot) takes(c rune) bool {
+	switch s.ch {
+	case '9':
+		return unicode.IsDigit(c)
+	case 'A':
+		return unicode.IsLetter(c)
+	}
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// The characters typed into the slots of the mask, from text that may have
+// some or all of the literals in it. Characters that do not fit are dropped
+func (m mask) raw(text string) string {
+	raw := []rune{}
+	i := 0
+	for _, c := range text {
+		for i < len(m) && m[i].literal && m[i].ch != c {
+			i++
+		}
+		if i >= len(m) {
+			break
+		}
+		if m[i].literal {
+			i++
+			continue
+		}
+		if m[i].takes(c) {
+			raw = append(raw, c)
+			i++
+		}
+	}
+	return string(raw)
+}
+
+// The raw characters laid out in the mask, up to the last one that was typed
+func (m mask) format(raw string) string {
+	out := []rune{}
+	chars := []rune(raw)
+	for _, s := range m {
+		if len(chars) == 0 {
+			break
+		}
+		if s.literal {
+			out = append(out, s.ch)
+			continue
+		}
+		out = append(out, chars[0])
+		chars = chars[1:]
+	}
+	return string(out)
+}
+
+// The position in the formatted text just after the n'th raw character
+func (m mask) position(formatted string, n int) int {
+	length := len([]rune(formatted))
+	for i := 0; i < length && i < len(m) && n > 0; i++ {
+		if !m[i].literal {
+			n--
+			if n == 0 {
+				return i + 1
+			}
+		}
+	}
+	if n <= 0 {
+		return 0
+	}
+	return length
+}
+
+// The number of characters that the mask takes
+func (m mask) slots() int {
+	n := 0
+	for _, s := range m {
+		if !s.literal {
+			n++
+		}
+	}
+	return n
+}
+
+// What the mask looks like before anything is typed, such as "(__) ____ ____"
+func (m mask) blank() string {
+	out := []rune{}
+	for _, s := range m {
+		if s.literal {
+			out = append(out, s.ch)
+		} else {
+			out = append(out, '_')
+		}
+	}
+	return string(out)
+}
+
+// Add a text input that takes its value in the pattern of a mask, such as "(99) 9999 9999".
+// It binds the formatted value, or just the characters that were typed with BindRaw
+func (r *EditRow) AddMasked(span int, label string, model string, pattern string) *EditRow {
+	f := &EditField{
+		Span:        span,
+		Label:       label,
+		Type:        "text",
+		Model:       model,
+		Mask:        pattern,
+		Placeholder: parseMask(pattern).blank(),
+	}
+	r.Fields = append(r.Fields, f)
+	return r
+}
+
+// BindRaw makes the last masked field added to the row bind just the characters
+// that were typed, without the literals of the mask
+func (r *EditRow) BindRaw() *EditRow {
+	if f := r.last(); f != nil {
+		f.MaskRaw = true
+	}
+	return r
+}
+
+// MaskLength - the length of the formatted value of a masked field
+func (e *EditField) MaskLength() int {
+	return len(parseMask(e.Mask))
+}
+
+// InputMode - the keyboard to show for a masked field, which is numeric if it only takes digits
+func (e *EditField) InputMode() string {
+	m := parseMask(e.Mask)
+	for _, s := range m {
+		if !s.literal && s.ch != '9' {
+			return ""
+		}
+	}
+	if m.slots() == 0 {
+		return ""
+	}
+	return "numeric"
+}
+
+// The text that a field binds, which for a masked field is laid out in the
+// mask, or just the typed characters if it binds raw
+func (e *EditField) bindText(text string) string {
+	if e.Mask == "" {
+		return text
+	}
+	m := parseMask(e.Mask)
+	raw := m.raw(text)
+	if e.MaskRaw {
+		return raw
+	}
+	return m.format(raw)
+}
+
+// Whether the text fills the mask of the field
+func (e *EditField) maskComplete(text string) bool {
+	m := parseMask(e.Mask)
+	return len([]rune(m.raw(text))) == m.slots()
+}
+
+// The message when a masked field has not been filled in
+func (e *EditField) maskMessage() string {
+	return fmt.Sprintf(T("Must be in the form %s"), strings.Replace(parseMask(e.Mask).blank(), "_", "#", -1))
+}
+
+// Lay out the values loaded into masked fields in their masks
+func (f *EditForm) maskValues() {
+	for _, field := range f.allFields() {
+		field.maskValue()
+	}
+}
+
+// Lay the value of a masked field out in its mask
+func (e *EditField) maskValue() {
+	if e.Mask != "" && e.Value != "" {
+		m := parseMask(e.Mask)
+		e.Value = m.format(m.raw(e.Value))
+	}
+}
+
+// Keep each masked field in its mask as it is typed into or pasted into
+func (f *EditForm) decorateMasks() {
+	for _, field := range f.allFields() {
+		if field.Mask == "" || field.Readonly || field.IsHidden() {
+			continue
+		}
+		el := f.find(`[name="` + field.Model + `"]`)
+		if el == nil {
+			continue
+		}
+		m := parseMask(field.Mask)
+		before := m.raw(el.Value())
+		// where the cursor was before the key, to tell Delete from Backspace
+		at := -1
+		f.listeners.addNode(el, "keydown", func(dom.Event) {
+			at = el.SelectionStart()
+		})
+		f.listeners.addNode(el, "input", func(dom.Event) {
+			before = maskInput(m, el, before, at)
+			at = -1
+		})
+	}
+}
+
+// Lay out what is in the input in the mask, keeping the cursor after the same typed
+// character, and return the typed characters. Deleting a literal of the mask deletes
+// the typed character next to it: Backspace moves the cursor back from at, where it was
+// before the key, and deletes the one in front, while Delete leaves it and deletes the one after
+func maskInput(m mask, el node, before string, at int) string {
+	text := el.Value()
+	cursor := el.SelectionStart()
+	runes := []rune(text)
+	if cursor < 0 || cursor > len(runes) {
+		cursor = len(runes)
+	}
+	raw := []rune(m.raw(text))
+	n := len([]rune(m.raw(string(runes[:cursor]))))
+	formatted := m.format(string(raw))
+	deleted := len(runes) < len([]rune(m.format(before)))
+	stay := false
+	if deleted && string(raw) == before {
+		switch {
+		case cursor == at && n < len(raw):
+			raw = append(raw[:n], raw[n+1:]...)
+			stay = true
+		case n > 0:
+			raw = append(raw[:n-1], raw[n:]...)
+			n--
+		}
+		formatted = m.format(string(raw))
+	}
+	if formatted != text {
+		el.SetValue(formatted)
+	}
+	pos := m.position(formatted, n)
+	if stay && cursor <= len([]rune(formatted)) {
+		// Delete leaves the cursor where it was
+		pos = cursor
+	}
+	el.SetSelectionRange(pos, pos)
+	return string(raw)
+}
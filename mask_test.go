@@ -0,0 +1,119 @@
+package formulate
+
+import "testing"
+
+func TestMaskFormat(t *testing.T) {
+	cases := []struct {
+		mask, text, raw, formatted string
+	}{
+		{"(99) 9999 9999", "0298765432", "0298765432", "(02) 9876 5432"},
+		{"(99) 9999 9999", "(02) 9876-5432", "0298765432", "(02) 9876 5432"},
+		{"(99) 9999 9999", "02 98", "0298", "(02) 98"},
+		{"AAA-9999", "abc1234", "abc1234", "abc-1234"},
+		{"AAA-9999", "ab1c234", "abc234", "abc-234"},
+		{"99 999 999 999", "51 824 753 556 extra", "51824753556", "51 824 753 556"},
+		{`\9**-99`, "9A1-23", "A123", "9A1-23"},
+	}
+	for _, c := range cases {
+		m := parseMask(c.mask)
+		raw := m.raw(c.text)
+		if raw != c.raw {
+			t.Errorf("%q raw(%q) = %q, want %q", c.mask, c.text, raw, c.raw)
+		}
+		if got := m.format(raw); got != c.formatted {
+			t.Errorf("%q format(%q) = %q, want %q", c.mask, raw, got, c.formatted)
+		}
+	}
+	if got := parseMask("(99) 9999").blank(); got != "(__) ____" {
+		t.Errorf("blank = %q", got)
+	}
+}
+
+type testContactDetails struct {
+	Phone    string
+	Postcode string
+}
+
+func TestMaskedInput(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f := &EditForm{}
+	f.New("fa-phone", "Contact")
+	f.Row(2).
+		AddMasked(1, "Phone", "Phone", "(99) 9999 9999").
+		AddMasked(1, "Postcode", "Postcode", "9999").BindRaw()
+	data := &testContactDetails{Phone: "0298765432"}
+	f.Render("edit-form", "#form", data)
+
+	phone := fakeField(t, doc, `[name="Phone"]`)
+	if phone.Value() != "(02) 9876 5432" || phone.attrs["placeholder"] != "(__) ____ ____" || phone.attrs["inputmode"] != "numeric" {
+		t.Fatalf("Phone = %q %v", phone.Value(), phone.attrs)
+	}
+	typeInto := func(n *fakeNode, value string, cursor int) {
+		n.SetValue(value)
+		n.SetSelectionRange(cursor, cursor)
+		for _, cb := range n.listeners["input"] {
+			cb(nil)
+		}
+	}
+
+	// typing in the middle keeps the cursor after the character typed
+	typeInto(phone, "(02) 98176 5432", 8)
+	if phone.Value() != "(02) 9817 6543" || phone.SelectionStart() != 8 {
+		t.Errorf("after typing: %q cursor %d", phone.Value(), phone.SelectionStart())
+	}
+	// deleting the space in front of the cursor deletes the digit before it
+	typeInto(phone, "(02) 98176543", 9)
+	if phone.Value() != "(02) 9816 543" || phone.SelectionStart() != 8 {
+		t.Errorf("after deleting a literal: %q cursor %d", phone.Value(), phone.SelectionStart())
+	}
+	// pasting formatted text in
+	typeInto(phone, "+02-1234-5678", 13)
+	if phone.Value() != "(02) 1234 5678" || phone.SelectionStart() != 14 {
+		t.Errorf("after pasting: %q cursor %d", phone.Value(), phone.SelectionStart())
+	}
+
+	// Delete in front of a literal deletes the digit after it, and leaves the cursor
+	deleteAt := func(n *fakeNode, value string, cursor int) {
+		n.SetSelectionRange(cursor, cursor)
+		for _, cb := range n.listeners["keydown"] {
+			cb(nil)
+		}
+		typeInto(n, value, cursor)
+	}
+	deleteAt(phone, "(02)1234 5678", 4)
+	if phone.Value() != "(02) 2345 678" || phone.SelectionStart() != 4 {
+		t.Errorf("after Delete of a literal: %q cursor %d", phone.Value(), phone.SelectionStart())
+	}
+	// and Backspace still deletes the digit before it
+	typeInto(phone, "(02) 1234 5678", 14)
+	phone.SetSelectionRange(10, 10)
+	for _, cb := range phone.listeners["keydown"] {
+		cb(nil)
+	}
+	typeInto(phone, "(02) 12345678", 9)
+	if phone.Value() != "(02) 1235 678" || phone.SelectionStart() != 8 {
+		t.Errorf("after Backspace of a literal: %q cursor %d", phone.Value(), phone.SelectionStart())
+	}
+	typeInto(phone, "(02) 1234 5678", 14)
+
+	postcode := fakeField(t, doc, `[name="Postcode"]`)
+	typeInto(postcode, "20a0", 4)
+	if postcode.Value() != "200" {
+		t.Errorf("Postcode = %q", postcode.Value())
+	}
+	if errs := f.Validate(); errs["Postcode"] == "" || errs["Phone"] != "" {
+		t.Errorf("Validate = %v", errs)
+	}
+	typeInto(postcode, "2000", 4)
+
+	got := &testContactDetails{}
+	f.Bind(got)
+	if got.Phone != "(02) 1234 5678" || got.Postcode != "2000" {
+		t.Errorf("bound %+v", *got)
+	}
+	m := map[string]interface{}{}
+	f.Bind(m)
+	if m["Phone"] != "(02) 1234 5678" || m["Postcode"] != "2000" {
+		t.Errorf("bound %v", m)
+	}
+}
@@ -0,0 +1,238 @@
+package formulate
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// PhotoOptions controls how a photo is processed in a canvas
+// before it is set on the preview image, and so before it is bound
+type PhotoOptions struct {
+	MaxWidth       int     // 0 = no limit
+	MaxHeight      int     // 0 = no limit
+	Quality        float64 // 0.0 - 1.0, defaults to 0.8
+	Format         string  // image/jpeg (default) or image/webp
+	FixOrientation bool    // rotate according to the EXIF orientation tag
+}
+
+// Replace the photo change handler with one that resizes the photo first
+func (f *EditForm) decoratePhoto(field *EditField, preview *dom.HTMLImageElement) {
+	el := f.query(`[name="` + field.Model + `"]`)
+	if el == nil {
+		print("There is no DOM element called '", field.Model+"' to read the photo from")
+		return
+	}
+
+	f.listeners.add(el, "change", func(evt dom.Event) {
+		files := evt.Target().Underlying().Get("files")
+		if files.Length() == 0 {
+			return
+		}
+		reader := dom.Global().Get("FileReader").New()
+		var release func()
+		onload, release := dom.Func(func(dom.Value) {
+			release()
+			field.PhotoOpts.process(reader.Get("result").String(), func(src string) {
+				preview.Set("src", src)
+				showElement(f.CurrentTheme(), preview, true)
+				f.fieldChanged(field)
+				if f.AttachCB != nil {
+					go f.AttachCB()
+				}
+			})
+		})
+		reader.Set("onload", onload)
+		reader.Call("readAsDataURL", files.Index(0))
+	})
+}
+
+// Scale, rotate and compress the photo in a canvas, and pass the result to done
+func (o *PhotoOptions) process(dataURL string, done func(string)) {
+	quality := o.Quality
+	if quality <= 0 || quality > 1 {
+		quality = 0.8
+	}
+	format := o.Format
+	if format == "" {
+		format = "image/jpeg"
+	}
+
+	orientation := 1
+	if o.FixOrientation && !browserOrientsImages() {
+		orientation = dataURLOrientation(dataURL)
+	}
+
+	img := dom.Global().Get("Image").New()
+	// the image ends with onload or onerror, so let go of the callbacks then
+	var releaseLoad, releaseError func()
+	release := func() {
+		releaseLoad()
+		releaseError()
+	}
+	onload, releaseLoad := dom.Func(func(dom.Value) {
+		release()
+		width, height := img.Get("naturalWidth").Int(), img.Get("naturalHeight").Int()
+
+		// limits apply to the photo the right way up
+		var w, h int
+		if orientation >= 5 {
+			h, w = fitWithin(height, width, o.MaxWidth, o.MaxHeight)
+		} else {
+			w, h = fitWithin(width, height, o.MaxWidth, o.MaxHeight)
+		}
+
+		canvas := dom.GetWindow().Document().CreateElement("canvas").(*dom.HTMLCanvasElement)
+		if orientation >= 5 {
+			canvas.Set("width", h)
+			canvas.Set("height", w)
+		} else {
+			canvas.Set("width", w)
+			canvas.Set("height", h)
+		}
+		ctx := canvas.GetContext("2d")
+		a, b, c, d, e, f := orientationTransform(orientation, w, h)
+		ctx.Call("transform", a, b, c, d, e, f)
+		ctx.Call("drawImage", img, 0, 0, w, h)
+		done(canvas.Call("toDataURL", format, quality).String())
+	})
+	onerror, releaseError := dom.Func(func(dom.Value) {
+		release()
+		print("failed to load photo for resizing, using it as is")
+		done(dataURL)
+	})
+	img.Set("onload", onload)
+	img.Set("onerror", onerror)
+	img.Set("src", dataURL)
+}
+
+// Browsers that support the image-orientation property already apply
+// the EXIF orientation when drawing into a canvas
+func browserOrientsImages() bool {
+	css := dom.Global().Get("CSS")
+	if dom.IsUndefined(css) {
+		return false
+	}
+	return css.Call("supports", "image-orientation", "from-image").Bool()
+}
+
+// fitWithin scales w x h down to fit within maxW x maxH, keeping the aspect ratio
+func fitWithin(w, h, maxW, maxH int) (int, int) {
+	if maxW > 0 && w > maxW {
+		h = h * maxW / w
+		w = maxW
+	}
+	if maxH > 0 && h > maxH {
+		w = w * maxH / h
+		h = maxH
+	}
+	return w, h
+}
+
+// orientationTransform returns the canvas transform that draws an image of
+// w x h the right way up for the given EXIF orientation
+func orientationTransform(orientation, w, h int) (a, b, c, d, e, f int) {
+	switch orientation {
+	case 2:
+		return -1, 0, 0, 1, w, 0
+	case 3:
+		return -1, 0, 0, -1, w, h
+	case 4:
+		return 1, 0, 0, -1, 0, h
+	case 5:
+		return 0, 1, 1, 0, 0, 0
+	case 6:
+		return 0, 1, -1, 0, h, 0
+	case 7:
+		return 0, -1, -1, 0, h, w
+	case 8:
+		return 0, -1, 1, 0, 0, w
+	}
+	return 1, 0, 0, 1, 0, 0
+}
+
+// Decode just the head of a JPEG data URL and read the EXIF orientation from it
+func dataURLOrientation(dataURL string) int {
+	comma := strings.Index(dataURL, ",")
+	if comma < 0 || !strings.Contains(dataURL[:comma], "image/jpeg") {
+		return 1
+	}
+	b64 := dataURL[comma+1:]
+	// the EXIF block lives in the first 64k
+	if len(b64) > 87384 {
+		b64 = b64[:87384]
+	}
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		print("cannot decode photo", err.Error())
+		return 1
+	}
+	return exifOrientation(data)
+}
+
+// exifOrientation finds the orientation tag in the APP1 segment of a JPEG
+// and returns 1 (normal) if there isnt one
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xDA || size < 2 {
+			// start of scan - no more metadata
+			return 1
+		}
+		if marker == 0xE1 && pos+4+size-2 <= len(data) {
+			if o := tiffOrientation(data[pos+4 : pos+2+size]); o > 0 {
+				return o
+			}
+		}
+		pos += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(seg []byte) int {
+	if len(seg) < 14 || string(seg[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := seg[6:]
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	// compare the offset before it becomes an int, which is 32 bits with GopherJS
+	// and would go negative for a large offset
+	offset := order.Uint32(tiff[4:])
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return 0
+	}
+	ifd := int(offset)
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
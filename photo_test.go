@@ -0,0 +1,34 @@
+package formulate
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// An Exif segment with one IFD entry for the orientation, at the offset given
+func testExif(offset uint32, orientation uint16) []byte {
+	seg := make([]byte, 6+8+2+12+2)
+	copy(seg, "Exif\x00\x00II*\x00")
+	tiff := seg[6:]
+	binary.LittleEndian.PutUint32(tiff[4:], offset)
+	binary.LittleEndian.PutUint16(tiff[8:], 1)
+	binary.LittleEndian.PutUint16(tiff[10:], 0x0112)
+	binary.LittleEndian.PutUint16(tiff[12:], 3)
+	binary.LittleEndian.PutUint32(tiff[14:], 1)
+	binary.LittleEndian.PutUint16(tiff[18:], orientation)
+	return seg
+}
+
+func TestTiffOrientation(t *testing.T) {
+	if got := tiffOrientation(testExif(8, 6)); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+	for _, offset := range []uint32{0xFFFFFFF0, 0x80000000, 1000} {
+		if got := tiffOrientation(testExif(offset, 6)); got != 0 {
+			t.Errorf("offset %#x: orientation = %d", offset, got)
+		}
+	}
+	if got := tiffOrientation([]byte("Exif\x00\x00II")); got != 0 {
+		t.Errorf("short segment: orientation = %d", got)
+	}
+}
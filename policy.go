@@ -0,0 +1,176 @@
+package formulate
+
+import "strings"
+
+// Access - what a role can do with a field
+type Access int
+
+const (
+	Editable Access = iota
+	ReadOnly
+	Hidden
+)
+
+// Policy decides which fields the roles of the current user can edit, only read,
+// or not see at all. Rules are checked in the order they were added, and the first
+// one that matches decides. Fields that no rule matches are editable
+type Policy struct {
+	Roles []string
+	rules []policyRule
+}
+
+type policyRule struct {
+	model  string
+	access Access
+	roles  []string
+	when   func(roles []string) bool
+}
+
+// NewPolicy - a policy for a user with these roles
+func NewPolicy(roles ...string) *Policy {
+	return &Policy{Roles: roles}
+}
+
+// Field adds a rule giving the roles access to the field. With no roles,
+// the rule is for everyone. The model can have wildcards, such as "*" or "Address.*"
+func (p *Policy) Field(model string, access Access, roles ...string) *Policy {
+	p.rules = append(p.rules, policyRule{model: model, access: access, roles: roles})
+	return p
+}
+
+// FieldWhen adds a rule giving access to the field when the predicate is true
+// for the roles of the user
+func (p *Policy) FieldWhen(model string, access Access, when func(roles []string) bool) *Policy {
+	p.rules = append(p.rules, policyRule{model: model, access: access, when: when})
+	return p
+}
+
+// Access returns what the user can do with the field. A nil policy allows everything
+func (p *Policy) Access(model string) Access {
+	if p == nil {
+		return Editable
+	}
+	for _, r := range p.rules {
+		if r.matches(model, p.Roles) {
+			return r.access
+		}
+	}
+	return Editable
+}
+
+// HasRole reports whether the user has the role
+func (p *Policy) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (r policyRule) matches(model string, roles []string) bool {
+	if !modelMatches(r.model, model) {
+		return false
+	}
+	if r.when != nil {
+		return r.when(roles)
+	}
+	if len(r.roles) == 0 {
+		return true
+	}
+	for _, want := range r.roles {
+		for _, have := range roles {
+			if want == have {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPolicy sets the policy that decides which fields can be edited or seen.
+// It is applied on Render, and Bind never writes a field that the policy does not let the user edit
+func (f *EditForm) SetPolicy(p *Policy) *EditForm {
+	f.Policy = p
+	return f
+}
+
+// What the policy lets the user do with a field
+func (f *EditForm) access(field *EditField) Access {
+	if field.Model == "" {
+		return Editable
+	}
+	return f.Policy.Access(field.Model)
+}
+
+// Make a field readonly while the form is in display mode or the policy
+// does not let it be edited, and hide it if the policy says so. Undo that when
+// they no longer apply
+func (f *EditForm) lockField(field *EditField) {
+	access := f.access(field)
+	field.hidden = access == Hidden
+	lock := f.DisplayMode || access != Editable
+	switch {
+	case lock && !field.Readonly:
+		field.Readonly = true
+		field.locked = true
+	case !lock && field.locked:
+		field.Readonly = false
+		field.locked = false
+	}
+}
+
+// IsHidden reports whether the policy hides the field, so that it is not rendered
+func (e *EditField) IsHidden() bool {
+	return e.hidden
+}
+
+// SetPolicy sets the policy, which hides the columns that the user cannot see
+func (f *ListForm) SetPolicy(p *Policy) *ListForm {
+	f.Policy = p
+	return f
+}
+
+// VisibleCols - the columns that the policy lets the user see, less those
+// that are hidden at the breakpoint the list is laid out for
+func (f *ListForm) VisibleCols() []*ListCol {
+	cols := []*ListCol{}
+	for _, c := range f.allowedCols() {
+		if !c.hiddenAt(f.breakpoint) {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// The columns that the policy lets the user see
+func (f *ListForm) allowedCols() []*ListCol {
+	cols := []*ListCol{}
+	for _, c := range f.Cols {
+		if f.Policy.Access(c.Model) != Hidden {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// The name that a generated template is kept under, which changes with the
+// breakpoint, and with the columns that the policy hides
+func (f *ListForm) templateName(name string) string {
+	hidden := []string{}
+	for _, c := range f.Cols {
+		if f.Policy.Access(c.Model) == Hidden {
+			hidden = append(hidden, c.Model)
+		}
+	}
+	if f.breakpoint != "" {
+		name += "@" + f.breakpoint
+	}
+	if len(hidden) == 0 {
+		return name
+	}
+	return name + "-without-" + strings.Join(hidden, ",")
+}
@@ -0,0 +1,194 @@
+package formulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPolicyAccess(t *testing.T) {
+	p := NewPolicy("clerk").
+		Field("Rate", Editable, "manager").
+		Field("Rate", Hidden).
+		Field("Address.*", ReadOnly, "clerk").
+		FieldWhen("Notes", ReadOnly, func(roles []string) bool { return len(roles) == 1 })
+	cases := map[string]Access{
+		"Rate":           Hidden,
+		"Address.Street": ReadOnly,
+		"Notes":          ReadOnly,
+		"Name":           Editable,
+	}
+	for model, want := range cases {
+		if got := p.Access(model); got != want {
+			t.Errorf("Access(%q) = %v, want %v", model, got, want)
+		}
+	}
+	p.Roles = append(p.Roles, "manager")
+	if got := p.Access("Rate"); got != Editable {
+		t.Errorf("manager Access(Rate) = %v", got)
+	}
+	if (*Policy)(nil).Access("Rate") != Editable {
+		t.Error("a nil policy should allow everything")
+	}
+}
+
+func TestPolicyRenderAndBind(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Notes: "Check seals", Status: 1, Priority: 1, Rate: 10}
+	f := newTestForm(job).SetPolicy(NewPolicy("clerk").
+		Field("Rate", Hidden).
+		Field("Name", ReadOnly).
+		Field("Status", ReadOnly, "clerk"))
+	f.Render("edit-form", "#form", job)
+
+	if doc.QuerySelector(`[name="Rate"]`) != nil {
+		t.Error("a hidden field was rendered")
+	}
+	if _, ok := fakeField(t, doc, `[name="Name"]`).attrs["readonly"]; !ok {
+		t.Error("Name is not readonly")
+	}
+	if _, ok := fakeField(t, doc, `[name="Status"]`).attrs["disabled"]; !ok {
+		t.Error("Status can still be changed")
+	}
+
+	// a tampered DOM does not get past Bind
+	fakeField(t, doc, `[name="Name"]`).SetValue("Pump rebuild")
+	fakeField(t, doc, `[name="Status"]`).SetValue("3")
+	fakeField(t, doc, `[name="Notes"]`).SetValue("Replace seals")
+	got := &testJob{}
+	f.Bind(got)
+	if got.Name != "" || got.Status != 0 || got.Rate != 0 {
+		t.Errorf("protected fields were bound: %+v", *got)
+	}
+	if got.Notes != "Replace seals" {
+		t.Errorf("Notes = %q", got.Notes)
+	}
+	m := map[string]interface{}{}
+	f.Bind(m)
+	if _, ok := m["Name"]; ok {
+		t.Errorf("protected fields were bound into a map: %v", m)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := f.RenderPrint(buf, job); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), ">Rate<") {
+		t.Error("a hidden field was printed")
+	}
+}
+
+func TestPolicyWithoutStructData(t *testing.T) {
+	for name, data := range map[string]interface{}{
+		"nil": nil,
+		"map": map[string]interface{}{"Name": "Pump service", "Rate": 10.0, "Visits": 2.0},
+	} {
+		doc := setupFakeDOM(t, `<div id="form"></div>`)
+		f := newTestForm(&testJob{}).SetPolicy(NewPolicy("clerk").
+			Field("Rate", Hidden).
+			Field("Visits", Hidden).
+			Field("Site", ReadOnly))
+		f.Render("edit-form", "#form", data)
+
+		for _, model := range []string{"Rate", "Visits"} {
+			if doc.QuerySelector(`[name="`+model+`"]`) != nil {
+				t.Errorf("%s data: the hidden field %s was rendered", name, model)
+			}
+		}
+		if _, ok := fakeField(t, doc, `[name="Site"]`).attrs["readonly"]; !ok {
+			t.Errorf("%s data: the panel field Site is not readonly", name)
+		}
+	}
+}
+
+// A form with each kind of input, on the form and on a swapper panel
+func newLockTestForm() *EditForm {
+	f := &EditForm{}
+	f.New("fa-lock", "Locks")
+	f.Row(3).
+		AddInput(1, "Name", "Name").
+		AddTextarea(1, "Notes", "Notes").
+		AddSelect(1, "Status", "Status", testStatuses, "ID", "Name", 1, 1)
+	f.Row(3).
+		AddGroupedSelect(1, "Group", "Group", []SelectGroup{{Title: "A", Options: []SelectOption{{0, "Zero"}}}}, 0).
+		AddCheck(1, "Urgent", "Urgent").
+		AddRadio(1, "Priority", "Priority", testStatuses, "ID", "Name", 1)
+	f.Row(3).
+		AddNumber(1, "Hours", "Hours", "1").
+		AddDate(1, "Due", "Due").
+		AddPhoto(1, "Photo", "Photo")
+
+	swapper := &Swapper{Name: "Where"}
+	swapper.AddPanel("Site").AddRow(4).
+		AddInput(1, "Site", "Site").
+		AddTextarea(1, "Directions", "Directions").
+		AddSelect(1, "Region", "Region", testStatuses, "ID", "Name", 1, 1).
+		AddCheck(1, "On Site", "OnSite")
+	swapper.Panels[0].AddRow(3).
+		AddRadio(1, "Access", "Access", testStatuses, "ID", "Name", 1).
+		AddNumber(1, "Visits", "Visits", "1").
+		AddDate(1, "Visited", "Visited")
+	f.Row(1).AddSwapper(1, "Where", swapper)
+	return f
+}
+
+// The attribute that stops each input of newLockTestForm from being changed
+var lockAttrs = map[string]string{
+	"Name":       "readonly",
+	"Notes":      "readonly",
+	"Status":     "disabled",
+	"Group":      "disabled",
+	"Urgent":     "disabled",
+	"Priority":   "disabled",
+	"Hours":      "readonly",
+	"Due":        "readonly",
+	"Photo":      "disabled",
+	"Site":       "readonly",
+	"Directions": "readonly",
+	"Region":     "disabled",
+	"OnSite":     "disabled",
+	"Access":     "disabled",
+	"Visits":     "readonly",
+	"Visited":    "readonly",
+}
+
+func TestPolicyReadOnlyInputs(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f := newLockTestForm().SetPolicy(NewPolicy("clerk").Field("*", ReadOnly))
+	f.Render("edit-form", "#form", nil)
+
+	for model, attr := range lockAttrs {
+		els := doc.QuerySelectorAll(`[name="` + model + `"]`)
+		if len(els) == 0 {
+			t.Errorf("there is no input for %s", model)
+		}
+		for _, el := range els {
+			if _, ok := el.(*fakeNode).attrs[attr]; !ok {
+				t.Errorf("%s is not %s", model, attr)
+			}
+		}
+	}
+}
+
+func TestPolicyHidesListColumns(t *testing.T) {
+	f := &ListForm{}
+	f.New("fa-list", "Jobs").
+		Column("Name", "Name").
+		DateColumn("Due", "Due").
+		BoolColumn("Urgent", "Urgent")
+	name := f.templateName("jobs")
+	f.SetPolicy(NewPolicy("clerk").Field("Due", Hidden, "clerk"))
+	if cols := f.VisibleCols(); len(cols) != 2 || cols[1].Model != "Urgent" {
+		t.Errorf("visible columns = %v", cols)
+	}
+	if f.templateName("jobs") == name {
+		t.Error("the generated template is shared with a policy that hides other columns")
+	}
+	buf := &bytes.Buffer{}
+	if err := f.RenderPrint(buf, []testListRow{{ID: 1, Name: "Pump service"}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "<th>Due</th>") {
+		t.Error("a hidden column was printed")
+	}
+}
@@ -0,0 +1,341 @@
+package formulate
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+const printStyle = `<style>
+.formulate-print { font-family: sans-serif; color: #000; background: #fff }
+.formulate-print h1 { font-size: 1.4em; margin: 0 0 0.5em }
+.formulate-print h2 { font-size: 1.1em; margin: 1em 0 0.3em }
+.formulate-print .print-section.print-panel { break-inside: avoid; page-break-inside: avoid }
+.formulate-print .print-row { display: flex; break-inside: avoid; page-break-inside: avoid }
+.formulate-print .print-field { box-sizing: border-box; padding: 0.3em 0.5em; border-bottom: 1px solid #ccc }
+.formulate-print .print-label { font-size: 0.8em; color: #555 }
+.formulate-print .print-value { white-space: pre-wrap; min-height: 1.2em }
+.formulate-print img.print-photo { max-width: 8cm; max-height: 6cm }
+.formulate-print img.print-signature { max-width: 6cm; max-height: 3cm }
+.formulate-print table { width: 100%; border-collapse: collapse }
+.formulate-print thead { display: table-header-group }
+.formulate-print tr { break-inside: avoid; page-break-inside: avoid }
+.formulate-print th, .formulate-print td { border-bottom: 1px solid #ccc; text-align: left; padding: 0.3em }
+.formulate-print td img { max-height: 2cm; max-width: 4cm }
+</style>`
+
+// Only the print document is printed, and it is not shown on screen
+const printPageStyle = `<style>
+@media screen { .formulate-print-page { display: none } }
+@media print { body > :not(.formulate-print-page) { display: none !important } }
+</style>`
+
+var editPrintTemplate = template.Must(template.New("edit-print").Parse(printStyle + `
+<div class="formulate-print">
+  <h1>{{.Title}}</h1>
+  {{range .Sections}}
+  <div class="print-section{{if .Title}} print-panel{{end}}">
+    {{if .Title}}<h2>{{.Title}}</h2>{{end}}
+    {{range .Rows}}
+    <div class="print-row">
+      {{range .}}
+      <div class="print-field" style="width: {{.Width}}%">
+        <div class="print-label">{{.Label}}</div>
+        <div class="print-value">
+          {{- .Text -}}
+          {{$class := .ImageClass}}{{range .Images}}<img class="{{$class}}" src="{{.}}" alt="">{{end}}
+          {{if .Files}}<ul>{{range .Files}}<li>{{.}}</li>{{end}}</ul>{{end}}
+        </div>
+      </div>
+      {{end}}
+    </div>
+    {{end}}
+  </div>
+  {{end}}
+</div>`))
+
+var listPrintTemplate = template.Must(template.New("list-print").Parse(printStyle + `
+<div class="formulate-print">
+  {{if .Title}}<h1>{{.Title}}</h1>{{end}}
+  <table>
+    <thead>
+      <tr>{{range .Headings}}<th>{{.}}</th>{{end}}</tr>
+    </thead>
+    <tbody>
+      {{range .Rows}}
+      <tr>{{range .}}<td>{{.Text}}{{range .Images}}<img src="{{.}}" alt="">{{end}}</td>{{end}}</tr>
+      {{end}}
+    </tbody>
+  </table>
+</div>`))
+
+type printDoc struct {
+	Title    string
+	Sections []printSection
+}
+
+type printSection struct {
+	Title string
+	Rows  [][]printField
+}
+
+type printField struct {
+	Label      string
+	Width      string
+	Text       string
+	Images     []template.URL
+	ImageClass string
+	Files      []string
+}
+
+// RenderPrint writes the form for the data as a static label and value document,
+// for printing. It needs no DOM, so it can also be run on the server
+func (f *EditForm) RenderPrint(w io.Writer, data interface{}) error {
+	f.load(data)
+	doc := printDoc{Title: f.Title}
+	main := printSection{}
+	for _, row := range f.Rows {
+		fields := []printField{}
+		for _, field := range row.Fields {
+			if field.Type == "swapper" && field.Swapper != nil {
+				// each panel is a section of its own, so that pages can break between them
+				if len(main.Rows) > 0 {
+					doc.Sections = append(doc.Sections, main)
+					main = printSection{}
+				}
+				for _, p := range field.Swapper.Panels {
+					section := printSection{Title: p.Name}
+					if field.Label != "" {
+						section.Title = field.Label + " - " + p.Name
+					}
+					for _, r := range p.Rows {
+						if pf := printFields(r, data); len(pf) > 0 {
+							section.Rows = append(section.Rows, pf)
+						}
+					}
+					doc.Sections = append(doc.Sections, section)
+				}
+				continue
+			}
+			if pf, ok := printFieldOf(row, field, data); ok {
+				fields = append(fields, pf)
+			}
+		}
+		if len(fields) > 0 {
+			main.Rows = append(main.Rows, fields)
+		}
+	}
+	if len(main.Rows) > 0 {
+		doc.Sections = append(doc.Sections, main)
+	}
+	return editPrintTemplate.Execute(w, doc)
+}
+
+// Print prints the form for the data, as the document from RenderPrint
+func (f *EditForm) Print(data interface{}) {
+	buf := &bytes.Buffer{}
+	if err := f.RenderPrint(buf, data); err != nil {
+		print("Print:", err.Error())
+		return
+	}
+	printHTML(buf.String())
+}
+
+func printFields(row *EditRow, data interface{}) []printField {
+	fields := []printField{}
+	for _, field := range row.Fields {
+		if pf, ok := printFieldOf(row, field, data); ok {
+			fields = append(fields, pf)
+		}
+	}
+	return fields
+}
+
+// The label and value to print for a field, or false if it does not print
+func printFieldOf(row *EditRow, field *EditField, data interface{}) (printField, bool) {
+	span := row.Span
+	if span < 1 {
+		span = 1
+	}
+	pf := printField{
+		Label: field.Label,
+		Width: strconv.FormatFloat(float64(field.Span)*100/float64(span), 'f', 2, 64),
+	}
+	if field.IsHidden() {
+		return pf, false
+	}
+	switch field.Type {
+	case "div", "button", "swapper":
+		return pf, false
+	case "photo":
+		pf.ImageClass = "print-photo"
+		src := field.Value // kept over a change of display mode
+		if src == "" {
+			src = photoSource(modelValue(data, field.Model))
+		}
+		if src != "" {
+			pf.Images = append(pf.Images, template.URL(src))
+		}
+	case "signature":
+		pf.ImageClass = "print-signature"
+		if field.Value != "" {
+			pf.Images = append(pf.Images, template.URL(field.Value))
+		}
+	case "files":
+		for _, ff := range field.Files {
+			pf.Files = append(pf.Files, ff.Filename)
+		}
+	default:
+		pf.Text = textOf(field)
+	}
+	return pf, true
+}
+
+// The value kept on a field as text, the way it reads on the form
+func textOf(field *EditField) string {
+	switch field.Type {
+	case "select":
+		return field.GetSelected()
+	case "groupselect":
+		key, _ := strconv.Atoi(field.Value)
+		for _, g := range field.Group {
+			for _, o := range g.Options {
+				if o.ID == key {
+					return o.Name
+				}
+			}
+		}
+		return ""
+	case "radio":
+		key, _ := strconv.Atoi(field.Value)
+		for _, o := range field.Options {
+			if o.Key == key {
+				return o.Display
+			}
+		}
+		return ""
+	case "checkbox":
+		return locale.FormatBool(field.Checked || field.Value != "")
+	case "number", "date", "datetime-local":
+		return displayOf(field)
+	}
+	return field.Value
+}
+
+// The image of a photo field, which is a string or a FileField
+func photoSource(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Struct:
+		if d := v.FieldByName("Data"); d.Kind() == reflect.String {
+			return d.String()
+		}
+	}
+	return ""
+}
+
+type printList struct {
+	Title    string
+	Headings []string
+	Rows     [][]printField
+}
+
+// RenderPrint writes the list for the data as a plain table, for printing,
+// with the headings repeated at the top of each page.
+// If data is nil, the data from the last Render is used
+func (f *ListForm) RenderPrint(w io.Writer, data interface{}) error {
+	if data == nil {
+		data = f.Data
+	}
+	doc := printList{Title: f.Title}
+	cols := f.allowedCols()
+	for _, col := range cols {
+		doc.Headings = append(doc.Headings, col.Heading)
+	}
+	rows := reflect.Indirect(reflect.ValueOf(data))
+	if rows.Kind() == reflect.Slice || rows.Kind() == reflect.Array {
+		for i := 0; i < rows.Len(); i++ {
+			item := rows.Index(i).Interface()
+			cells := []printField{}
+			for _, col := range cols {
+				cells = append(cells, printCell(col, modelValue(item, col.Model)))
+			}
+			doc.Rows = append(doc.Rows, cells)
+		}
+	}
+	return listPrintTemplate.Execute(w, doc)
+}
+
+// Print prints the list, as the table from RenderPrint
+func (f *ListForm) Print(data interface{}) {
+	buf := &bytes.Buffer{}
+	if err := f.RenderPrint(buf, data); err != nil {
+		print("Print:", err.Error())
+		return
+	}
+	printHTML(buf.String())
+}
+
+// The printed value of a column
+func printCell(col *ListCol, v reflect.Value) printField {
+	cell := printField{}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return cell
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return cell
+	}
+	switch {
+	case col.IsImg && col.IsArray:
+		for i := 0; i < v.Len(); i++ {
+			if src := photoSource(reflect.Indirect(v.Index(i)).FieldByName(col.Fieldname)); src != "" {
+				cell.Images = append(cell.Images, template.URL(src))
+			}
+		}
+	case col.IsImg:
+		if src := photoSource(v); src != "" {
+			cell.Images = append(cell.Images, template.URL(src))
+		}
+	case col.IsIcon || col.Format == "avatar":
+		// nothing worth printing
+	case col.IsBool:
+		if v.Kind() == reflect.Bool && v.Bool() {
+			cell.Text = locale.FormatBool(true)
+		}
+	default:
+		if t, ok := toTime(v.Interface()); ok {
+			if !t.IsZero() {
+				cell.Text = locale.FormatDate(t, locale.DateLayout)
+			}
+		} else if !v.IsZero() {
+			cell.Text = fmt.Sprint(v.Interface())
+		}
+	}
+	return cell
+}
+
+// Print the html on its own, then take it away again
+func printHTML(html string) {
+	if !hasDOM() {
+		print("Print: there is no browser to print from")
+		return
+	}
+	w := dom.GetWindow()
+	doc := w.Document()
+	body := doc.QuerySelector("body")
+	div := doc.CreateElement("div")
+	div.SetAttribute("class", "formulate-print-page")
+	div.SetInnerHTML(printPageStyle + html)
+	body.AppendChild(div)
+	w.Print()
+	body.RemoveChild(div)
+}
@@ -0,0 +1,88 @@
+package formulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditFormRenderPrint(t *testing.T) {
+	due := time.Date(2017, 3, 14, 0, 0, 0, 0, time.UTC)
+	job := &testJob{
+		Name:        "Pump service",
+		Notes:       "Check the seals",
+		Status:      2,
+		Priority:    3,
+		Rate:        1234.5,
+		Urgent:      true,
+		Due:         &due,
+		Attachments: []FileField{{Filename: "a.pdf"}},
+		Photo:       FileField{Data: "data:image/jpeg;base64,AAAA"},
+		Site:        "Depot",
+	}
+	f := newTestForm(job)
+	buf := &bytes.Buffer{}
+	if err := f.RenderPrint(buf, job); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<h1>Job</h1>",
+		`<div class="print-label">Status</div>`,
+		"Closed",           // the select prints its option, not its key
+		"Hold",             // and so does the radio
+		"1,234.50",         // numbers are formatted
+		"Tue, Mar 14 2017", // and dates
+		"Yes",
+		"a.pdf",
+		`<img class="print-photo" src="data:image/jpeg;base64,AAAA"`,
+		`<div class="print-section print-panel">`,
+		"<h2>Where - Site</h2>",
+		"<h2>Where - Office</h2>",
+		"Depot",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("print is missing %q", want)
+		}
+	}
+	for _, chrome := range []string{"<input", "<select", "<textarea", "<button"} {
+		if strings.Contains(out, chrome) {
+			t.Errorf("print has %s", chrome)
+		}
+	}
+}
+
+type testListRow struct {
+	ID     int
+	Name   string
+	Due    time.Time
+	Urgent bool
+}
+
+func TestListFormRenderPrint(t *testing.T) {
+	f := &ListForm{}
+	f.New("fa-list", "Jobs").
+		Column("Name", "Name").
+		DateColumn("Due", "Due").
+		BoolColumn("Urgent", "Urgent")
+	rows := []testListRow{
+		{1, "Pump service", time.Date(2017, 3, 14, 0, 0, 0, 0, time.UTC), true},
+		{2, "Valve <check>", time.Time{}, false},
+	}
+	buf := &bytes.Buffer{}
+	if err := f.RenderPrint(buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"thead { display: table-header-group }",
+		"<tr><th>Name</th><th>Due</th><th>Urgent</th></tr>",
+		"<tr><td>Pump service</td><td>Tue, Mar 14 2017</td><td>Yes</td></tr>",
+		"<tr><td>Valve &lt;check&gt;</td><td></td><td></td></tr>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("print is missing %q in\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package formulate
+
+import "reflect"
+
+// SetLive makes the form bind each field into the data that it was rendered with as the
+// field is edited, rather than waiting for Bind. Fields on swapper panels are bound live
+// if the panel binds with the form. Set it before Render
+func (f *EditForm) SetLive(live bool) *EditForm {
+	f.Live = live
+	return f
+}
+
+// Refresh writes the fields whose values have changed in the data that the form was
+// rendered with, such as after the code has recalculated a total, and leaves the other
+// fields as they are. Subscriptions from OnFieldChange are told of the changes
+func (f *EditForm) Refresh() {
+	if !f.IsRendered || f.data == nil {
+		print("ERROR: Refresh() called before the form is rendered")
+		return
+	}
+	f.paintFields(f.allFields(), f.data)
+}
+
+// Load the fields from the data, and write those whose values have changed into the form
+func (f *EditForm) paintFields(fields []*EditField, data interface{}) {
+	_, fromMap := dataMap(data)
+	changed := map[*EditField]bool{}
+	order := []*EditField{}
+	repaint := false
+	for _, field := range fields {
+		switch field.Type {
+		case "div", "swapper", "button":
+			continue
+		}
+		if field.Model == "" || f.access(field) == Hidden {
+			continue
+		}
+		v := modelValue(data, field.Model)
+		if !v.IsValid() {
+			// not in the data, as Render leaves it
+			continue
+		}
+		before := stateOf(field)
+		f.setFieldValue(field, v, fromMap)
+		if reflect.DeepEqual(before, stateOf(field)) {
+			continue
+		}
+		changed[field] = true
+		order = append(order, field)
+		// display mode shows the values as text, and a signature is drawn on its pad
+		repaint = repaint || f.DisplayMode || field.Type == "signature"
+	}
+	if len(order) == 0 {
+		return
+	}
+
+	if repaint {
+		open := f.openPanels()
+		// keep what has been entered in the other fields
+		for _, field := range f.allFields() {
+			if !changed[field] {
+				f.keepValue(field)
+			}
+		}
+		f.paintAgain(open)
+	} else {
+		for _, field := range order {
+			f.paintValue(field)
+		}
+	}
+	for _, field := range order {
+		f.tellWatchers(field)
+	}
+}
+
+// Whether the field is bound into the data as it is edited
+func (f *EditForm) bindsLive(field *EditField) bool {
+	if !f.Live || f.data == nil || field.Model == "" || field.Readonly || f.access(field) != Editable {
+		return false
+	}
+	switch field.Type {
+	case "div", "swapper", "button":
+		return false
+	}
+	if p := f.panelOf(field); p != nil {
+		// Bind leaves panel fields out of map data
+		_, isMap := dataMap(f.data)
+		return p.BindWithForm && !isMap
+	}
+	return true
+}
+
+// Bind the field into the data that the form was rendered with, if the form is live
+func (f *EditForm) bindLive(field *EditField) {
+	if !f.bindsLive(field) {
+		return
+	}
+	if m, ok := dataMap(f.data); ok {
+		f.bindMapField(m, field)
+	} else {
+		ptrVal := reflect.ValueOf(f.data)
+		if ptrVal.Kind() != reflect.Ptr || ptrVal.IsNil() || ptrVal.Elem().Kind() != reflect.Struct {
+			return
+		}
+		f.bindField(ptrVal, field)
+	}
+	// so that Refresh compares the data with what is on the form
+	f.keepValue(field)
+}
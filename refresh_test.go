@@ -0,0 +1,151 @@
+package formulate
+
+import "testing"
+
+func TestRefresh(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Hours: 2, Rate: 80, Visits: 1}
+	f := newTestForm(job)
+	changes := []testChange{}
+	for _, model := range []string{"Name", "Notes", "Status", "Hours", "Rate", "Visits"} {
+		model := model
+		f.OnFieldChange(model, func(old, new interface{}) {
+			changes = append(changes, testChange{model, old, new})
+		})
+	}
+	f.Render("edit-form", "#form", job)
+
+	// typed on the form, but not bound
+	fakeField(t, doc, `[name="Notes"]`).SetValue("Check the seals")
+
+	job.Status = 2
+	job.Hours = 3
+	job.Rate = 92.5
+	job.Visits = 5
+	f.Refresh()
+
+	for sel, want := range map[string]string{
+		`[name="Status"]`: "2",
+		`[name="Hours"]`:  "3",
+		`[name="Rate"]`:   "92.50",
+		`[name="Visits"]`: "5",
+		`[name="Notes"]`:  "Check the seals",
+		`[name="Name"]`:   "Pump service",
+	} {
+		if got := fakeField(t, doc, sel).Value(); got != want {
+			t.Errorf("%s = %q, want %q", sel, got, want)
+		}
+	}
+	want := []testChange{
+		{"Status", 1, 2},
+		{"Hours", 2, 3},
+		{"Rate", 80.0, 92.5},
+		{"Visits", 1, 5},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %v, want %v", i, changes[i], want[i])
+		}
+	}
+
+	// nothing has changed since
+	changes = nil
+	f.Refresh()
+	if len(changes) != 0 {
+		t.Errorf("changes = %v, when nothing changed", changes)
+	}
+}
+
+func TestPanelPaint(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Visits: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+
+	panel := f.GetField("").Swapper.Panels[0]
+	panel.Paint(&testJob{Site: "Depot", OnSite: true, Visits: 2})
+	if got := fakeField(t, doc, `[name="Site"]`).Value(); got != "Depot" {
+		t.Errorf("Site = %q", got)
+	}
+	if !fakeField(t, doc, `[name="OnSite"]`).Checked() {
+		t.Error("OnSite was not checked")
+	}
+	if got := fakeField(t, doc, `[name="Visits"]`).Value(); got != "2" {
+		t.Errorf("Visits = %q", got)
+	}
+}
+
+func TestLiveBinding(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Visits: 1}
+	f := newTestForm(job)
+	f.SetLive(true)
+	f.GetField("").Swapper.Panels[0].BindWithForm = true
+	f.Render("edit-form", "#form", job)
+
+	edit := func(sel string, value string, event string) {
+		n := fakeField(t, doc, sel)
+		n.SetValue(value)
+		for _, cb := range n.listeners[event] {
+			cb(nil)
+		}
+	}
+	edit(`[name="Name"]`, "Pump rebuild", "input")
+	edit(`[name="Status"]`, "3", "change")
+	edit(`[name="Visits"]`, "4", "input")
+	if job.Name != "Pump rebuild" || job.Status != 3 || job.Visits != 4 {
+		t.Errorf("live bound %+v", *job)
+	}
+
+	// read only fields are left alone
+	edit(`[name="Ref"]`, "J-1", "input")
+	if job.Ref != "" {
+		t.Errorf("Ref was bound as %q", job.Ref)
+	}
+
+	// and the data that was bound live can be changed and refreshed
+	job.Visits = 6
+	f.Refresh()
+	if got := fakeField(t, doc, `[name="Visits"]`).Value(); got != "6" {
+		t.Errorf("Visits = %q", got)
+	}
+}
+
+func TestRefreshMap(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	// numbers decoded from JSON are float64
+	data := map[string]interface{}{"Name": "Pump service", "Hours": 3.0, "Rate": 80.0}
+	f := newTestForm(&testJob{})
+	changes := []testChange{}
+	f.OnFieldChange("Hours", func(old, new interface{}) {
+		changes = append(changes, testChange{"Hours", old, new})
+	})
+	f.Render("edit-form", "#form", data)
+
+	f.Refresh()
+	if len(changes) != 0 {
+		t.Errorf("changes = %v, when nothing changed", changes)
+	}
+	if got := fakeField(t, doc, `[name="Hours"]`).Value(); got != "3" {
+		t.Errorf("Hours = %q", got)
+	}
+	if got, err := f.GetValue("Hours"); err != nil || got != 3 {
+		t.Errorf("GetValue(Hours) = %v, %v", got, err)
+	}
+
+	data["Hours"] = 4.0
+	f.Refresh()
+	if got := fakeField(t, doc, `[name="Hours"]`).Value(); got != "4" {
+		t.Errorf("Hours = %q", got)
+	}
+	if len(changes) != 1 || changes[0] != (testChange{"Hours", 3, 4}) {
+		t.Errorf("changes = %v", changes)
+	}
+	f.Bind(data)
+	if data["Hours"] != 4 {
+		t.Errorf("Hours bound as %v (%T)", data["Hours"], data["Hours"])
+	}
+}
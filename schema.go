@@ -0,0 +1,246 @@
+package formulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// The most fields that go side by side, for the properties of a nested object
+const schemaRowSpan = 3
+
+// Matches most email addresses, without trying to be exact
+const emailPattern = `^[^@\s]+@[^@\s]+\.[^@\s]+$`
+
+// The part of JSON Schema that maps onto form fields
+type jsonSchema struct {
+	Type        schemaType       `json:"type"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Examples    []interface{}    `json:"examples"`
+	Format      string           `json:"format"`
+	Enum        []interface{}    `json:"enum"`
+	Properties  schemaProperties `json:"properties"`
+	Items       *jsonSchema      `json:"items"`
+	Required    []string         `json:"required"`
+	Minimum     *float64         `json:"minimum"`
+	Maximum     *float64         `json:"maximum"`
+	MultipleOf  *float64         `json:"multipleOf"`
+	MinLength   int              `json:"minLength"`
+	MaxLength   int              `json:"maxLength"`
+	Pattern     string           `json:"pattern"`
+	ReadOnly    bool             `json:"readOnly"`
+}
+
+// The type of a schema, which can be a list such as ["string", "null"]
+type schemaType string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaType(one)
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	for _, s := range many {
+		if s != "null" {
+			*t = schemaType(s)
+			break
+		}
+	}
+	return nil
+}
+
+// The properties of an object, in the order they are written in the schema
+type schemaProperties []schemaProperty
+
+type schemaProperty struct {
+	Name   string
+	Schema *jsonSchema
+}
+
+func (p *schemaProperties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		prop := schemaProperty{Name: tok.(string), Schema: &jsonSchema{}}
+		if err := dec.Decode(prop.Schema); err != nil {
+			return err
+		}
+		*p = append(*p, prop)
+	}
+	return nil
+}
+
+// FromJSONSchema builds an editform from a JSON Schema for an object, with
+// a field for each of its properties, and validation rules from its constraints.
+// Nested objects get rows of their own, with dotted models such as "address.city".
+// The form binds into a map[string]interface{} as well as a struct
+func FromJSONSchema(data []byte) (*EditForm, error) {
+	s := &jsonSchema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Type != "object" && s.Properties == nil {
+		return nil, fmt.Errorf("formulate: schema is for a %q, not an object", s.Type)
+	}
+	f := &EditForm{}
+	f.New("", s.Title)
+	if err := f.addSchemaFields(s, "", ""); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Add a row for each property of the object, and rows for any nested objects
+func (f *EditForm) addSchemaFields(s *jsonSchema, prefix string, labelPrefix string) error {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	var row *EditRow
+	for _, prop := range s.Properties {
+		ps := prop.Schema
+		label := ps.Title
+		if label == "" {
+			label = prop.Name
+		}
+		label = labelPrefix + label
+
+		if ps.Type == "object" {
+			if err := f.addSchemaFields(ps, prefix+prop.Name+".", label+" "); err != nil {
+				return err
+			}
+			row = nil
+			continue
+		}
+
+		field, err := schemaField(ps, prefix+prop.Name, label, required[prop.Name])
+		if err != nil {
+			return err
+		}
+		if field == nil {
+			continue
+		}
+		// top level fields get a row each, nested fields share rows
+		if prefix == "" || row == nil || len(row.Fields) >= schemaRowSpan {
+			span := 1
+			if prefix != "" {
+				span = schemaRowSpan
+			}
+			row = f.Row(span)
+		}
+		row.AddField(*field)
+	}
+	return nil
+}
+
+// The field for a property that is not an object, or nil if there is
+// no field that can hold it
+func schemaField(s *jsonSchema, model string, label string, required bool) (*EditField, error) {
+	field := &EditField{
+		Span:     1,
+		Label:    label,
+		Model:    model,
+		Readonly: s.ReadOnly,
+		Help:     s.Description,
+	}
+	if len(s.Examples) > 0 {
+		field.Placeholder = fmt.Sprint(s.Examples[0])
+	}
+
+	switch {
+	case len(s.Enum) > 0:
+		field.Type = "select"
+		if s.Type == "integer" || s.Type == "number" {
+			// numeric enums bind their own values as keys
+			for _, v := range s.Enum {
+				n, ok := v.(float64)
+				if !ok {
+					return nil, fmt.Errorf("formulate: enum value %v of %s is not a number", v, model)
+				}
+				if n != math.Trunc(n) {
+					// the values are the option keys, which are whole numbers
+					return nil, fmt.Errorf("formulate: enum value %v of %s is not a whole number", v, model)
+				}
+				field.Options = append(field.Options, &EditOption{Key: int(n), Display: fmt.Sprint(v)})
+			}
+			break
+		}
+		// start with nothing chosen, so that required means something
+		field.Options = append(field.Options, &EditOption{Key: -1, Display: ""})
+		for i, v := range s.Enum {
+			field.Enum = append(field.Enum, fmt.Sprint(v))
+			field.Options = append(field.Options, &EditOption{Key: i, Display: fmt.Sprint(v)})
+		}
+	case s.Type == "string":
+		switch s.Format {
+		case "date":
+			field.Type = "date"
+		case "date-time":
+			field.Type = "datetime-local"
+		case "email":
+			field.Type = "text"
+		default:
+			field.Type = "text"
+			if s.MaxLength > 255 {
+				field.Type = "textarea"
+			}
+		}
+	case s.Type == "integer":
+		field.Type = "number"
+		field.Step = "1"
+	case s.Type == "number":
+		field.Type = "number"
+		field.IsFloat = true
+		field.Step = "any"
+		if s.MultipleOf != nil {
+			field.Step = fmt.Sprint(*s.MultipleOf)
+		}
+	case s.Type == "boolean":
+		field.Type = "checkbox"
+	case s.Type == "array":
+		if s.Items == nil || s.Items.Type == "object" || s.Items.Type == "array" {
+			print("FromJSONSchema: no field for an array of objects", model)
+			return nil, nil
+		}
+		field.Type = "textarea"
+		field.ListOf = string(s.Items.Type)
+		if field.ListOf == "" {
+			field.ListOf = "string"
+		}
+	default:
+		print("FromJSONSchema: no field for", model, "of type", string(s.Type))
+		return nil, nil
+	}
+
+	rules := Rules{
+		Required:  required && field.Type != "checkbox",
+		Min:       s.Minimum,
+		Max:       s.Maximum,
+		MinLength: s.MinLength,
+		MaxLength: s.MaxLength,
+		Pattern:   s.Pattern,
+	}
+	if s.Format == "email" && rules.Pattern == "" {
+		rules.Pattern = emailPattern
+		rules.Message = "Must be an email address"
+	}
+	if rules != (Rules{}) {
+		field.Rules = &rules
+	}
+	field.Label = strings.TrimSpace(field.Label)
+	return field, nil
+}
@@ -0,0 +1,248 @@
+package formulate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testSchema = `{
+	"title": "Contact",
+	"type": "object",
+	"required": ["name", "email", "kind"],
+	"properties": {
+		"name":     {"type": "string", "title": "Name", "maxLength": 20},
+		"email":    {"type": "string", "format": "email"},
+		"kind":     {"type": "string", "enum": ["person", "company"]},
+		"age":      {"type": ["integer", "null"], "minimum": 18},
+		"score":    {"type": "number"},
+		"active":   {"type": "boolean"},
+		"born":     {"type": "string", "format": "date"},
+		"seen":     {"type": "string", "format": "date-time"},
+		"tags":     {"type": "array", "items": {"type": "string"}},
+		"code":     {"type": "string", "pattern": "^[A-Z]{3}$"},
+		"address":  {
+			"type": "object",
+			"title": "Address",
+			"required": ["city"],
+			"properties": {
+				"street": {"type": "string"},
+				"city":   {"type": "string", "title": "City"}
+			}
+		}
+	}
+}`
+
+type testAddress struct {
+	Street string
+	City   string
+}
+
+type testContact struct {
+	Name    string `json:"name"`
+	Email   string
+	Kind    string
+	Age     int
+	Score   float64
+	Active  bool
+	Born    *time.Time
+	Seen    time.Time
+	Tags    []string
+	Code    string
+	Address *testAddress `json:"address"`
+}
+
+func TestFromJSONSchemaFields(t *testing.T) {
+	f, err := FromJSONSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Title != "Contact" {
+		t.Errorf("Title = %q", f.Title)
+	}
+
+	models := []string{}
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			models = append(models, field.Model)
+		}
+	}
+	want := []string{"name", "email", "kind", "age", "score", "active", "born", "seen", "tags", "code", "address.street", "address.city"}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("models in order\n got %v\nwant %v", models, want)
+	}
+	if n := len(f.Rows[len(f.Rows)-1].Fields); n != 2 {
+		t.Errorf("address has %d fields in its row, want 2", n)
+	}
+
+	for _, tc := range []struct {
+		model, typ string
+	}{
+		{"name", "text"}, {"email", "text"}, {"kind", "select"}, {"age", "number"},
+		{"score", "number"}, {"active", "checkbox"}, {"born", "date"},
+		{"seen", "datetime-local"}, {"tags", "textarea"}, {"address.city", "text"},
+	} {
+		if got := f.GetField(tc.model).Type; got != tc.typ {
+			t.Errorf("%s is a %q, want %q", tc.model, got, tc.typ)
+		}
+	}
+	if !f.GetField("score").IsFloat || f.GetField("age").IsFloat {
+		t.Error("only numbers should be floats")
+	}
+	if f.GetField("address.city").Label != "Address City" {
+		t.Errorf("nested label = %q", f.GetField("address.city").Label)
+	}
+	if r := f.GetField("age").Rules; r == nil || r.Min == nil || *r.Min != 18 || r.Required {
+		t.Errorf("age rules = %+v", r)
+	}
+	if r := f.GetField("address.city").Rules; r == nil || !r.Required {
+		t.Errorf("nested required was not carried over")
+	}
+	if f.GetField("active").Rules != nil {
+		t.Errorf("unconstrained field has rules")
+	}
+}
+
+func TestFromJSONSchemaErrors(t *testing.T) {
+	for _, src := range []string{
+		`{"type": "string"}`,
+		`{"type": "object", "properties": {"n": {"type": "integer", "enum": ["a"]}}}`,
+		`{"type": "object", "properties": {"n": {"type": "number", "enum": [1, 1.5]}}}`,
+		`not json`,
+	} {
+		if _, err := FromJSONSchema([]byte(src)); err == nil {
+			t.Errorf("expected an error from %s", src)
+		}
+	}
+}
+
+func TestSchemaFormValidate(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f, err := FromJSONSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Render("edit-form", "#form", map[string]interface{}{})
+
+	if n := fakeField(t, doc, `[name="name"]`); n.attrs["maxlength"] != "20" || n.attrs["aria-required"] != "true" {
+		t.Errorf("rules not rendered on the input: %v", n.attrs)
+	}
+
+	fakeField(t, doc, `[name="name"]`).SetValue("A name that is far too long")
+	fakeField(t, doc, `[name="email"]`).SetValue("nobody")
+	fakeField(t, doc, `[name="age"]`).SetValue("12")
+	fakeField(t, doc, `[name="code"]`).SetValue("abc")
+	errs := f.Validate()
+	for _, model := range []string{"name", "email", "kind", "age", "code", "address.city"} {
+		if errs[model] == "" {
+			t.Errorf("%s should be invalid", model)
+		}
+	}
+	if len(errs) != 6 {
+		t.Errorf("errors = %v", errs)
+	}
+	if msg := fakeField(t, doc, "#"+f.UID+`-address\.city-error`).textContent(); msg != errs["address.city"] {
+		t.Errorf("error shown = %q, want %q", msg, errs["address.city"])
+	}
+	if fakeField(t, doc, `[name="age"]`).attrs["aria-invalid"] != "true" {
+		t.Error("age is not marked invalid")
+	}
+
+	fakeField(t, doc, `[name="name"]`).SetValue("Acme")
+	fakeField(t, doc, `[name="email"]`).SetValue("info@acme.example")
+	fakeField(t, doc, `[name="kind"]`).SetValue("1")
+	fakeField(t, doc, `[name="age"]`).SetValue("")
+	fakeField(t, doc, `[name="code"]`).SetValue("ACM")
+	fakeField(t, doc, `[name="address.city"]`).SetValue("Perth")
+	if errs := f.Validate(); len(errs) != 0 {
+		t.Errorf("errors = %v", errs)
+	}
+	if _, ok := fakeField(t, doc, `[name="age"]`).attrs["aria-invalid"]; ok {
+		t.Error("age is still marked invalid")
+	}
+}
+
+func TestSchemaFormBindMap(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f, err := FromJSONSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{
+		"name":    "Acme",
+		"email":   "info@acme.example",
+		"kind":    "company",
+		"age":     float64(40),
+		"score":   4.5,
+		"active":  true,
+		"born":    "1980-05-06",
+		"seen":    "2020-01-02T03:04:00Z",
+		"tags":    []interface{}{"red", "blue"},
+		"code":    "ACM",
+		"address": map[string]interface{}{"street": "1 Main St", "city": "Perth"},
+	}
+	f.Render("edit-form", "#form", data)
+	if v := fakeField(t, doc, `[name="seen"]`).Value(); v != "2020-01-02T03:04" {
+		t.Errorf("seen = %q", v)
+	}
+
+	got := map[string]interface{}{}
+	f.Bind(got)
+	want := map[string]interface{}{
+		"name":    "Acme",
+		"email":   "info@acme.example",
+		"kind":    "company",
+		"age":     40,
+		"score":   4.5,
+		"active":  true,
+		"born":    "1980-05-06",
+		"seen":    "2020-01-02T03:04:00Z",
+		"tags":    []interface{}{"red", "blue"},
+		"code":    "ACM",
+		"address": map[string]interface{}{"street": "1 Main St", "city": "Perth"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bind map\n got %v\nwant %v", got, want)
+	}
+
+	fakeField(t, doc, `[name="kind"]`).SetValue("0")
+	fakeField(t, doc, `[name="tags"]`).SetValue("green\n\n yellow ")
+	fakeField(t, doc, `[name="address.city"]`).SetValue("Albany")
+	f.Bind(&got)
+	if got["kind"] != "person" || !reflect.DeepEqual(got["tags"], []interface{}{"green", "yellow"}) ||
+		got["address"].(map[string]interface{})["city"] != "Albany" {
+		t.Errorf("edited bind map = %v", got)
+	}
+}
+
+func TestSchemaFormBindStruct(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	f, err := FromJSONSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := time.Date(1980, 5, 6, 0, 0, 0, 0, time.UTC)
+	c := &testContact{
+		Name:    "Acme",
+		Email:   "info@acme.example",
+		Kind:    "company",
+		Age:     40,
+		Score:   4.5,
+		Active:  true,
+		Born:    &born,
+		Seen:    time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC),
+		Tags:    []string{"red", "blue"},
+		Code:    "ACM",
+		Address: &testAddress{Street: "1 Main St", City: "Perth"},
+	}
+	f.Render("edit-form", "#form", c)
+	if v := fakeField(t, doc, `[name="tags"]`).Value(); v != "red\nblue" {
+		t.Errorf("tags = %q", v)
+	}
+
+	got := &testContact{}
+	f.Bind(got)
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("bind struct\n got %+v\nwant %+v", got, c)
+	}
+}
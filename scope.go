@@ -0,0 +1,152 @@
+package formulate
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+var formCount int64
+
+// Generate an id prefix that is unique to each form instance
+func newUID() string {
+	return fmt.Sprintf("formulate-%d", atomic.AddInt64(&formCount, 1))
+}
+
+// Generate an id prefix for a form rendered on the server, which cannot clash with
+// the forms that the browser renders itself. Hydrate takes it from the markup
+func newServerUID() string {
+	return fmt.Sprintf("formulate-ssr-%d", atomic.AddInt64(&formCount, 1))
+}
+
+// The fixed ids that the templates had before each form had a UID. Each is now
+// the form's UID followed by the name, with a class of the same name
+var fixedIDs = map[string]bool{
+	"action-grid":    true,
+	"legend":         true,
+	"titletext":      true,
+	"confirm-delete": true,
+}
+
+// Resolve one of the old fixed ids, such as "#action-grid", to the element of the
+// form with the UID, or to the class when there is no form. Other selectors are left alone
+func scopedSelector(uid string, selector string) string {
+	name := strings.TrimPrefix(selector, "#")
+	if name == selector || !fixedIDs[name] {
+		return selector
+	}
+	if uid == "" {
+		return "." + name
+	}
+	return "#" + uid + "-" + name
+}
+
+// Find the first element matching the selector inside root,
+// or in the whole document if there is no root yet
+func querySelector(root dom.Element, selector string) dom.Element {
+	if root == nil {
+		return dom.GetWindow().Document().QuerySelector(selector)
+	}
+	return root.QuerySelector(selector)
+}
+
+// Find all the elements matching the selector inside root,
+// or in the whole document if there is no root yet
+func querySelectorAll(root dom.Element, selector string) []dom.Element {
+	if root == nil {
+		return dom.GetWindow().Document().QuerySelectorAll(selector)
+	}
+	return root.QuerySelectorAll(selector)
+}
+
+// Look up an element inside the form
+func (f *EditForm) query(selector string) dom.Element {
+	return querySelector(f.Root, selector)
+}
+
+func (f *EditForm) queryAll(selector string) []dom.Element {
+	return querySelectorAll(f.Root, selector)
+}
+
+// Selector for one of the generated ids of this form.
+// Models can be dotted paths, so the dots are escaped
+func (f *EditForm) id(name string) string {
+	return "#" + f.UID + "-" + strings.Replace(name, ".", `\.`, -1)
+}
+
+// Attach the form to the element it is rendered into
+func (f *EditForm) attach(selector string) {
+	if f.UID == "" {
+		f.UID = newUID()
+	}
+	f.root = document().QuerySelector(selector)
+	f.Root = elementOf(f.root)
+}
+
+// Look up a node inside root, or in the whole document if there is no root yet
+func findNode(root node, selector string) node {
+	if root == nil {
+		return document().QuerySelector(selector)
+	}
+	return root.QuerySelector(selector)
+}
+
+func findNodes(root node, selector string) []node {
+	if root == nil {
+		return document().QuerySelectorAll(selector)
+	}
+	return root.QuerySelectorAll(selector)
+}
+
+// Look up a node inside the form, for rendering and binding
+func (f *EditForm) find(selector string) node {
+	return findNode(f.root, selector)
+}
+
+func (f *EditForm) findAll(selector string) []node {
+	return findNodes(f.root, selector)
+}
+
+func (s *Swapper) query(selector string) node {
+	return findNode(s.root, selector)
+}
+
+func (p *Panel) query(selector string) node {
+	return findNode(p.root, selector)
+}
+
+func (p *Panel) queryAll(selector string) []node {
+	return findNodes(p.root, selector)
+}
+
+func (f *ListForm) query(selector string) dom.Element {
+	return querySelector(f.Root, selector)
+}
+
+func (f *ListForm) id(name string) string {
+	return "#" + f.UID + "-" + name
+}
+
+func (f *ListForm) attach(selector string) {
+	if f.UID == "" {
+		f.UID = newUID()
+	}
+	f.Root = dom.GetWindow().Document().QuerySelector(selector)
+}
+
+func (f *TreeForm) query(selector string) dom.Element {
+	return querySelector(f.Root, selector)
+}
+
+func (f *TreeForm) id(name string) string {
+	return "#" + f.UID + "-" + name
+}
+
+func (f *TreeForm) attach(selector string) {
+	if f.UID == "" {
+		f.UID = newUID()
+	}
+	f.Root = dom.GetWindow().Document().QuerySelector(selector)
+}
@@ -0,0 +1,171 @@
+package formulate
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Wire up the drawing pad, clear button and preview of a signature field
+func (f *EditForm) decorateSignature(field *EditField) {
+	w := dom.GetWindow()
+
+	img, _ := f.query(`[name="` + field.Model + `Preview"]`).(*dom.HTMLImageElement)
+	if img == nil {
+		print("There is no DOM element called '", field.Model+"Preview' to write the signature into")
+		return
+	}
+	setSignatureImage(f.CurrentTheme(), img, field.Value)
+	if field.Readonly {
+		return
+	}
+
+	canvas, _ := f.query(`[name="` + field.Model + `"]`).(*dom.HTMLCanvasElement)
+	if canvas == nil {
+		print("There is no canvas called '", field.Model+"' to draw the signature on")
+		return
+	}
+	ctx := canvas.GetContext("2d")
+	ctx.Set("lineWidth", 2.5)
+	ctx.Set("lineCap", "round")
+	ctx.Set("lineJoin", "round")
+	ctx.Set("strokeStyle", "#000")
+
+	// Redraw any existing signature, so that it can be added to
+	if field.Value != "" {
+		existing := dom.Global().Get("Image").New()
+		var release func()
+		onload, release := dom.Func(func(dom.Value) {
+			release()
+			ctx.Call("drawImage", existing, 0, 0)
+		})
+		existing.Set("onload", onload)
+		existing.Set("src", field.Value)
+	}
+
+	// The canvas is scaled by CSS, so map the pointer back onto canvas pixels
+	point := func(evt dom.Event) (float64, float64) {
+		rect := canvas.GetBoundingClientRect()
+		x := evt.Underlying().Get("clientX").Float() - rect.Left
+		y := evt.Underlying().Get("clientY").Float() - rect.Top
+		if rect.Width == 0 || rect.Height == 0 {
+			return x, y
+		}
+		return x * canvas.Get("width").Float() / rect.Width, y * canvas.Get("height").Float() / rect.Height
+	}
+
+	drawing := false
+	f.listeners.add(canvas, "pointerdown", func(evt dom.Event) {
+		evt.PreventDefault()
+		drawing = true
+		canvas.Call("setPointerCapture", evt.Underlying().Get("pointerId"))
+		x, y := point(evt)
+		ctx.Call("beginPath")
+		ctx.Call("moveTo", x, y)
+		// so that a single tap leaves a dot
+		ctx.Call("lineTo", x+0.5, y+0.5)
+		ctx.Call("stroke")
+	})
+	f.listeners.add(canvas, "pointermove", func(evt dom.Event) {
+		if !drawing {
+			return
+		}
+		evt.PreventDefault()
+		x, y := point(evt)
+		ctx.Call("lineTo", x, y)
+		ctx.Call("stroke")
+	})
+	finish := func(evt dom.Event) {
+		if !drawing {
+			return
+		}
+		drawing = false
+		field.Value = canvas.Call("toDataURL", "image/png").String()
+		setSignatureImage(f.CurrentTheme(), img, field.Value)
+		f.fieldChanged(field)
+		if f.ChangeCB != nil {
+			f.ChangeCB(evt)
+		}
+	}
+	f.listeners.add(canvas, "pointerup", finish)
+	f.listeners.add(canvas, "pointercancel", finish)
+
+	if el := f.query(`[name="` + field.Model + `Clear"]`); el != nil {
+		f.listeners.add(el, "click", func(evt dom.Event) {
+			evt.PreventDefault()
+			ctx.Call("clearRect", 0, 0, canvas.Get("width"), canvas.Get("height"))
+			field.Value = ""
+			setSignatureImage(f.CurrentTheme(), img, field.Value)
+			f.fieldChanged(field)
+			if f.ChangeCB != nil {
+				f.ChangeCB(evt)
+			}
+		})
+	}
+
+	// Print the signature as a plain image rather than the drawing pad
+	f.listeners.add(w, "beforeprint", func(evt dom.Event) {
+		showElement(f.CurrentTheme(), canvas, false)
+		if field.Value != "" {
+			showElement(f.CurrentTheme(), img, true)
+		}
+	})
+	f.listeners.add(w, "afterprint", func(evt dom.Event) {
+		showElement(f.CurrentTheme(), canvas, true)
+		showElement(f.CurrentTheme(), img, false)
+	})
+}
+
+func setSignatureImage(t Theme, img *dom.HTMLImageElement, src string) {
+	if src == "" {
+		img.RemoveAttribute("src")
+		showElement(t, img, false)
+		return
+	}
+	img.Set("src", src)
+}
+
+// Read the signature data URL out of a string or FileField
+func getSignature(dataField reflect.Value) string {
+	switch dataField.Kind() {
+	case reflect.String:
+		return dataField.String()
+	case reflect.Struct:
+		if data := dataField.FieldByName("Data"); data.Kind() == reflect.String {
+			return data.String()
+		}
+	}
+	return ""
+}
+
+func setFromSignature(target reflect.Value, dataURL string) {
+
+	k := target.Kind()
+	switch k {
+	case reflect.String:
+		target.SetString(dataURL)
+	case reflect.Struct:
+		// should be a FileField
+		setFromString(target.FieldByName("Data"), dataURL)
+		filename, mimeType, size := "", "", 0
+		if dataURL != "" {
+			filename, mimeType = "signature.png", "image/png"
+			if comma := strings.Index(dataURL, ","); comma > -1 {
+				size = base64.StdEncoding.DecodedLen(len(dataURL) - comma - 1)
+			}
+		}
+		if fld := target.FieldByName("Filename"); fld.IsValid() {
+			setFromString(fld, filename)
+		}
+		if fld := target.FieldByName("Type"); fld.IsValid() {
+			setFromString(fld, mimeType)
+		}
+		if fld := target.FieldByName("Size"); fld.IsValid() {
+			setFromInt(fld, size)
+		}
+	default:
+		print("conversion of signature to unknown type", k.String())
+	}
+}
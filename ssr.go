@@ -0,0 +1,121 @@
+package formulate
+
+import (
+	"errors"
+	"io"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Whether there is a browser DOM, rather than the standard Go toolchain on a server
+func hasDOM() bool {
+	return dom.HasDOM()
+}
+
+// Find the element that RenderHTML markup was placed in, and the UID it was rendered with
+func hydrateRoot(selector string) (node, string) {
+	root := document().QuerySelector(selector)
+	if root == nil {
+		print("Could not find selector", selector)
+		return nil, ""
+	}
+	el := root.QuerySelector("[data-formulate]")
+	if el == nil {
+		print("No server rendered form inside", selector)
+		return root, ""
+	}
+	return root, el.GetAttribute("data-formulate")
+}
+
+// RenderHTML writes the form's markup for the data, without needing a DOM,
+// so that it can be rendered on the server. Use Hydrate in the browser to bring it to life.
+// The markup carries the UID, which is set before the call or generated, for Hydrate to find
+func (f *EditForm) RenderHTML(w io.Writer, template string, data interface{}) error {
+	if gt == nil {
+		return errors.New("No templates, call Templates() first")
+	}
+	t, err := gt(template)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errors.New("Invalid template")
+	}
+	if f.UID == "" {
+		f.UID = newServerUID()
+	}
+	f.load(data)
+	return t.Execute(w, f)
+}
+
+// Hydrate attaches the form to markup from RenderHTML, wiring up the callbacks
+// and data as Render would, but without rendering the template again
+func (f *EditForm) Hydrate(template string, selector string, data interface{}) {
+	f.listeners.removeAll()
+	f.rerender = func() {
+		f.Render(template, selector, data)
+	}
+	root, uid := hydrateRoot(selector)
+	if root == nil {
+		return
+	}
+	if uid == "" {
+		f.Render(template, selector, data)
+		return
+	}
+	f.root = root
+	f.Root = elementOf(root)
+	f.UID = uid
+	f.IsRendered = true
+	f.repaint = func() {
+		f.listeners.removeAll()
+		renderTemplateNode(template, f.root, f)
+		f.scope()
+		f.decorate(data)
+	}
+	f.load(data)
+	f.remember(data)
+	f.scope()
+	f.decorate(data)
+	scrollToTop(f.Root)
+}
+
+// RenderHTML writes the listform's markup for the data, without needing a DOM,
+// so that it can be rendered on the server. Use Hydrate in the browser to bring it to life
+func (f *ListForm) RenderHTML(w io.Writer, name string, data interface{}) error {
+	if generatedTemplates == nil {
+		return errors.New("No templates, call Templates() first")
+	}
+	f.Data = data
+	if f.UID == "" {
+		f.UID = newServerUID()
+	}
+	t := f.generateTemplate(name, true)
+	if t == nil {
+		return errors.New("Invalid template")
+	}
+	return t.Execute(w, f)
+}
+
+// Hydrate attaches the listform to markup from RenderHTML, wiring up the callbacks
+// as Render would, but without rendering the template again
+func (f *ListForm) Hydrate(name string, selector string, data interface{}) {
+	f.listeners.removeAll()
+	f.Data = data
+	f.rerender = func() {
+		f.Render(name, selector, data)
+	}
+	root, uid := hydrateRoot(selector)
+	if root == nil {
+		return
+	}
+	if uid == "" {
+		f.Render(name, selector, data)
+		return
+	}
+	f.Root = elementOf(root)
+	f.UID = uid
+	// the markup was laid out for this breakpoint, so a resize within it keeps it
+	f.breakpoint = CurrentBreakpoint()
+	f.decorate(selector)
+}
@@ -0,0 +1,81 @@
+package formulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTMLHydrate(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 2, Priority: 1, Hours: 3}
+	server := newTestForm(job)
+	buf := &bytes.Buffer{}
+	if err := server.RenderHTML(buf, "edit-form", job); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(server.UID, "formulate-ssr-") {
+		t.Errorf("server UID = %q", server.UID)
+	}
+
+	// the browser renders a form of its own as well as the server's
+	doc := setupFakeDOM(t, `<div id="form">`+buf.String()+`</div><div id="other"></div>`)
+	other := newTestForm(job)
+	other.Render("edit-form", "#other", job)
+	f := newTestForm(job)
+	f.Hydrate("edit-form", "#form", job)
+	if f.UID != server.UID {
+		t.Errorf("hydrated UID = %q, want %q", f.UID, server.UID)
+	}
+	if other.UID == f.UID {
+		t.Errorf("the forms share the UID %q", f.UID)
+	}
+	if doc.QuerySelector("#"+f.UID+"-Hours") == nil {
+		t.Error("the server markup has no field with the hydrated UID")
+	}
+
+	// the server markup is bound as a rendered form
+	fakeField(t, fakeField(t, doc, "#form"), `[name="Hours"]`).SetValue("5")
+	if got, err := f.GetValue("Hours"); err != nil || got != 5 {
+		t.Errorf("GetValue(Hours) = %v, %v", got, err)
+	}
+	bound := &testJob{}
+	f.Bind(bound)
+	if bound.Name != "Pump service" || bound.Status != 2 || bound.Hours != 5 {
+		t.Errorf("bound %+v", *bound)
+	}
+}
+
+func TestListFormHydrateTwice(t *testing.T) {
+	setupFakeDOM(t, `<div id="list"><table id="formulate-ssr-9-list-form" data-formulate="formulate-ssr-9"></table></div>`)
+	setWindowWidth(t, 800)
+	f := &ListForm{}
+	f.New("fa-list", "Jobs").Column("Name", "Name")
+	removed := 0
+	f.listeners = listeners{func() { removed++ }}
+
+	rows := []testListRow{{ID: 1, Name: "Pump service"}}
+	f.Hydrate("jobs", "#list", rows)
+	f.Hydrate("jobs", "#list", rows)
+	if removed != 1 || len(f.listeners) != 0 {
+		t.Errorf("the old listeners were removed %d times, and %d are left", removed, len(f.listeners))
+	}
+	if f.UID != "formulate-ssr-9" {
+		t.Errorf("UID = %q", f.UID)
+	}
+	if f.breakpoint != "tablet" {
+		t.Errorf("breakpoint = %q, so a resize would render again", f.breakpoint)
+	}
+}
+
+func TestListFormRenderHTMLWithoutTemplates(t *testing.T) {
+	saved := generatedTemplates
+	generatedTemplates = nil
+	t.Cleanup(func() { generatedTemplates = saved })
+
+	f := &ListForm{}
+	f.New("fa-wrench", "Jobs")
+	if err := f.RenderHTML(&bytes.Buffer{}, "jobs", nil); err == nil {
+		t.Error("RenderHTML without templates did not fail")
+	}
+}
@@ -0,0 +1,306 @@
+package formulate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SubmitOptions - how SubmitTo sends the form, and what it does with the reply
+type SubmitOptions struct {
+	Data      interface{}       // what the form binds into before it is sent, or a new map if nil
+	Multipart bool              // send multipart/form-data, with FileFields as file parts, rather than JSON
+	Headers   map[string]string // extra request headers, such as Authorization
+	Client    *http.Client      // defaults to http.DefaultClient
+	Success   func(resp *http.Response, body []byte)
+	Failure   func(err error)
+	// FieldErrors reads the messages by model from a 4xx reply.
+	// The default reads {"errors": {"model": "message"}}
+	FieldErrors func(body []byte) map[string]string
+}
+
+// SubmitError is the error from SubmitTo when the form is not accepted,
+// either by the validation rules or by the server
+type SubmitError struct {
+	Status int               // the HTTP status, or 0 if the form was not valid
+	Body   []byte            // the body of the reply
+	Fields map[string]string // messages by model
+	// Conflicts are the fields that differ from the record on the server,
+	// when it replies 409 Conflict or 412 Precondition Failed with the record it has
+	Conflicts []*Conflict
+}
+
+func (e *SubmitError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("formulate: %d fields are not valid", len(e.Fields))
+	}
+	return fmt.Sprintf("formulate: submit failed with %d %s", e.Status, http.StatusText(e.Status))
+}
+
+// SubmitTo validates the form, binds it, and sends the data to the url.
+// Errors for fields in a 4xx reply are shown on those fields. If the form has a version,
+// it is sent as If-Match, and a conflict reply with the saved record shows the conflicts. The Success or
+// Failure hook is called with the outcome, which is also announced to screen readers.
+// It blocks until there is a reply, so call it in a goroutine from an event
+func (f *EditForm) SubmitTo(url string, method string, options SubmitOptions) error {
+	err := f.submit(url, method, options)
+	if err != nil {
+		f.Announce(T("The form could not be saved"))
+		if options.Failure != nil {
+			options.Failure(err)
+		}
+	}
+	return err
+}
+
+func (f *EditForm) submit(url string, method string, options SubmitOptions) error {
+	f.ClearErrors()
+	if errs := f.Validate(); len(errs) > 0 {
+		return &SubmitError{Fields: errs}
+	}
+
+	data := options.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	f.Bind(data)
+	f.bindVersion(data)
+
+	var body io.Reader
+	contentType := "application/json"
+	if options.Multipart {
+		buf := &bytes.Buffer{}
+		w := multipart.NewWriter(buf)
+		if err := f.writeParts(w, data); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body = buf
+		contentType = w.FormDataContentType()
+	} else {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	if f.Version != "" {
+		req.Header.Set("If-Match", ifMatch(f.Version))
+	}
+	for k, v := range options.Headers {
+		req.Header.Set(k, v)
+	}
+	client := options.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	reply, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		serr := &SubmitError{Status: resp.StatusCode, Body: reply}
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			fieldErrors := options.FieldErrors
+			if fieldErrors == nil {
+				fieldErrors = defaultFieldErrors
+			}
+			serr.Fields = fieldErrors(reply)
+			for model, msg := range serr.Fields {
+				f.SetError(model, msg)
+			}
+		}
+		if resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusPreconditionFailed {
+			if theirs := f.decodeRecord(reply); theirs != nil {
+				serr.Conflicts = f.Conflicts(theirs)
+				f.conflict.version = f.replyVersion(resp, theirs)
+				f.ShowConflicts(serr.Conflicts)
+			}
+		}
+		return serr
+	}
+
+	// what was saved is now the version to compare with
+	if v := f.replyVersion(resp, f.decodeRecord(reply)); v != "" {
+		f.Version = v
+	}
+	f.keepValues()
+	f.original = f.fieldStates()
+
+	f.Announce(T("Saved"))
+	if options.Success != nil {
+		options.Success(resp, reply)
+	}
+	return nil
+}
+
+// Read {"errors": {"model": "message"}}, where a message can also be a list
+func defaultFieldErrors(body []byte) map[string]string {
+	reply := struct {
+		Errors map[string]interface{} `json:"errors"`
+	}{}
+	fields := map[string]string{}
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return fields
+	}
+	for model, v := range reply.Errors {
+		switch msg := v.(type) {
+		case string:
+			fields[model] = msg
+		case []interface{}:
+			if len(msg) > 0 {
+				fields[model] = fmt.Sprint(msg[0])
+			}
+		}
+	}
+	return fields
+}
+
+// Write a part for each field that binds, with FileFields as file parts
+func (f *EditForm) writeParts(w *multipart.Writer, data interface{}) error {
+	for _, field := range f.boundFields() {
+		v := modelValue(data, field.Model)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if !v.IsValid() {
+			continue
+		}
+		switch val := v.Interface().(type) {
+		case FileField:
+			if err := writeFilePart(w, field.Model, val); err != nil {
+				return err
+			}
+		case []FileField:
+			for _, ff := range val {
+				if err := writeFilePart(w, field.Model, ff); err != nil {
+					return err
+				}
+			}
+		case time.Time:
+			if err := w.WriteField(field.Model, val.Format(dateLayout(field))); err != nil {
+				return err
+			}
+		default:
+			if v.Kind() == reflect.Slice {
+				for i := 0; i < v.Len(); i++ {
+					if err := w.WriteField(field.Model, fmt.Sprint(v.Index(i).Interface())); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := w.WriteField(field.Model, fmt.Sprint(val)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Write a file part from the data url in a FileField
+func writeFilePart(w *multipart.Writer, name string, ff FileField) error {
+	if ff.Data == "" {
+		return nil
+	}
+	content := []byte(ff.Data)
+	mimeType := ff.Type
+	if strings.HasPrefix(ff.Data, "data:") {
+		comma := strings.Index(ff.Data, ",")
+		if comma < 0 {
+			return fmt.Errorf("formulate: bad data url in %s", name)
+		}
+		meta := ff.Data[len("data:"):comma]
+		if mimeType == "" {
+			mimeType = strings.Split(meta, ";")[0]
+		}
+		if strings.HasSuffix(meta, ";base64") {
+			b, err := base64.StdEncoding.DecodeString(ff.Data[comma+1:])
+			if err != nil {
+				return err
+			}
+			content = b
+		} else {
+			content = []byte(ff.Data[comma+1:])
+		}
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	filename := ff.Filename
+	if filename == "" {
+		filename = name
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(name), escapeQuotes(filename)))
+	h.Set("Content-Type", mimeType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(content)
+	return err
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// The fields that Bind reads, including those on swapper panels that bind with the form
+func (f *EditForm) boundFields() []*EditField {
+	fields := []*EditField{}
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if field.Type == "swapper" && field.Swapper != nil {
+				for _, p := range field.Swapper.Panels {
+					if !p.BindWithForm {
+						continue
+					}
+					for _, r := range p.Rows {
+						for _, sf := range r.Fields {
+							if !sf.Readonly && sf.Model != "" && f.access(sf) == Editable {
+								fields = append(fields, sf)
+							}
+						}
+					}
+				}
+				continue
+			}
+			if field.Readonly || field.Model == "" || field.Type == "div" || f.access(field) != Editable {
+				continue
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
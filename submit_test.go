@@ -0,0 +1,138 @@
+package formulate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSubmitToJSON(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	var got testJob
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Token") != "abc" {
+			t.Errorf("request = %s %v", r.Method, r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"id": 7}`))
+	}))
+	defer srv.Close()
+
+	job := &testJob{Name: "Pump service", Status: 2, Priority: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+	fakeField(t, doc, `[name="Name"]`).SetValue("Pump rebuild")
+
+	reply := ""
+	err := f.SubmitTo(srv.URL, "PUT", SubmitOptions{
+		Data:    &testJob{},
+		Headers: map[string]string{"X-Token": "abc"},
+		Success: func(resp *http.Response, body []byte) { reply = string(body) },
+		Failure: func(err error) { t.Errorf("failure hook called with %v", err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Pump rebuild" || got.Status != 2 {
+		t.Errorf("server got %+v", got)
+	}
+	if reply != `{"id": 7}` {
+		t.Errorf("success hook got %q", reply)
+	}
+}
+
+func TestSubmitToFieldErrors(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"errors": {"Name": ["is taken"], "Hours": "too many"}}`))
+	}))
+	defer srv.Close()
+
+	job := &testJob{Name: "Pump service", Status: 2, Priority: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+
+	var failed error
+	err := f.SubmitTo(srv.URL, "POST", SubmitOptions{
+		Data:    &testJob{},
+		Success: func(*http.Response, []byte) { t.Error("success hook called") },
+		Failure: func(err error) { failed = err },
+	})
+	serr, ok := err.(*SubmitError)
+	if !ok || failed != err {
+		t.Fatalf("err = %v, failure hook got %v", err, failed)
+	}
+	if serr.Status != http.StatusUnprocessableEntity || serr.Fields["Name"] != "is taken" || serr.Fields["Hours"] != "too many" {
+		t.Errorf("err = %+v", serr)
+	}
+	if fakeField(t, doc, `[name="Name"]`).attrs["aria-invalid"] != "true" {
+		t.Error("Name is not marked invalid")
+	}
+	if msg := fakeField(t, doc, "#"+f.UID+"-Hours-error").textContent(); msg != "too many" {
+		t.Errorf("Hours error = %q", msg)
+	}
+}
+
+func TestSubmitToMultipart(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.FormValue("Name") != "Pump service" || r.FormValue("Urgent") != "true" {
+			t.Errorf("values = %v", r.MultipartForm.Value)
+		}
+		files := r.MultipartForm.File["Attachments"]
+		if len(files) != 2 {
+			t.Errorf("got %d files", len(files))
+			return
+		}
+		if files[0].Filename != "a.txt" || files[0].Header.Get("Content-Type") != "text/plain" {
+			t.Errorf("file header = %v", files[0].Header)
+		}
+		fh, _ := files[1].Open()
+		content, _ := ioutil.ReadAll(fh)
+		if string(content) != "hello" {
+			t.Errorf("file content = %q", content)
+		}
+	}))
+	defer srv.Close()
+
+	job := &testJob{
+		Name:     "Pump service",
+		Status:   1,
+		Priority: 1,
+		Urgent:   true,
+		Attachments: []FileField{
+			{Filename: "a.txt", Type: "text/plain", Data: "data:text/plain;base64,aGk="},
+			{Filename: "b.txt", Data: "data:text/plain;base64,aGVsbG8="},
+		},
+	}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+	if err := f.SubmitTo(srv.URL, "POST", SubmitOptions{Data: &testJob{}, Multipart: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitToInvalid(t *testing.T) {
+	setupFakeDOM(t, `<div id="form"></div>`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("an invalid form was sent")
+	}))
+	defer srv.Close()
+
+	job := &testJob{Status: 1, Priority: 1}
+	f := newTestForm(job).SetRules("Name", Rules{Required: true})
+	f.Render("edit-form", "#form", job)
+	err := f.SubmitTo(srv.URL, "POST", SubmitOptions{Data: &testJob{}})
+	if serr, ok := err.(*SubmitError); !ok || serr.Status != 0 || serr.Fields["Name"] == "" {
+		t.Errorf("err = %v", err)
+	}
+}
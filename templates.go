@@ -1,11 +1,12 @@
 package formulate
 
 import (
+	"bytes"
 	"errors"
 	"html/template"
 
 	"github.com/go-humble/temple/temple"
-	"honnef.co/go/js/dom"
+	"github.com/steveoc64/formulate/dom"
 )
 
 var gt func(name string) (*temple.Template, error)
@@ -18,6 +19,9 @@ func Templates(g func(string) (*temple.Template, error)) {
 	generatedTemplates.AddFunc("safeURL", func(u string) template.URL {
 		return template.URL(u)
 	})
+	for name, fn := range localeFuncs() {
+		generatedTemplates.AddFunc(name, fn)
+	}
 }
 
 // Load a template and attach it to the specified element in the doc
@@ -47,9 +51,49 @@ func renderTemplateT(t *temple.Template, selector string, data interface{}) erro
 		return errors.New("Invalid selector")
 	}
 
-	if err := t.ExecuteEl(el, data); err != nil {
+	return executeTemplate(t, wrapElement(el), data)
+}
+
+// Load a template and attach it to the given element
+func renderTemplateEl(name string, el dom.Element, data interface{}) error {
+
+	t, err := gt(name)
+	if t == nil {
+		print("Failed to load template", name)
+		return errors.New("Invalid template")
+	}
+	if err != nil {
+		print(err.Error())
+		return err
+	}
+
+	return executeTemplate(t, wrapElement(el), data)
+}
+
+// Load a template and render it into the given node
+func renderTemplateNode(name string, n node, data interface{}) error {
+
+	t, err := gt(name)
+	if t == nil {
+		print("Failed to load template", name)
+		return errors.New("Invalid template")
+	}
+	if err != nil {
+		print(err.Error())
+		return err
+	}
+
+	return executeTemplate(t, n, data)
+}
+
+// Execute the template into the node. This is done with Execute rather than
+// temple's ExecuteEl, so that it works on any DOM backend
+func executeTemplate(t *temple.Template, n node, data interface{}) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
 		print(err.Error())
 		return err
 	}
+	n.SetInnerHTML(buf.String())
 	return nil
 }
@@ -0,0 +1,180 @@
+package formulate
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+// Theme supplies the CSS classes, icons and layout attributes that forms are
+// rendered with. Embed DefaultTheme to change only some of them.
+//
+// The parts passed to Class are: container, form, header, title, header-button,
+// label, input, check, help, footer, footer-column, buttons, button, button-primary, table,
+// modal, modal-content, modal-buttons, modal-button, modal-overlay,
+// and the state classes hidden, modal-show and swapper-show.
+//
+// The icons passed to Icon are: add, delete, edit, print, upload, file, remove, check and help.
+type Theme interface {
+	Class(part string) string
+	Icon(name string) template.HTML
+	TitleIcon(icon string) template.HTML
+	Row(span int) template.HTMLAttr
+	Field(rowSpan int, span int) template.HTMLAttr
+}
+
+// DefaultTheme is the Gridforms, Milligram and Font Awesome look
+type DefaultTheme struct{}
+
+var defaultClasses = map[string]string{
+	"container":      "data-container",
+	"form":           "grid-form",
+	"header":         "row data-table-header",
+	"title":          "column column-90",
+	"header-button":  "column col-center",
+	"help":           "field-help",
+	"footer":         "row",
+	"footer-column":  "column",
+	"buttons":        "button-bar",
+	"button":         "button-outline",
+	"button-primary": "button-primary",
+	"table":          "data-table",
+	"modal":          "md-modal md-effect-1",
+	"modal-content":  "grid-form md-content data-container",
+	"modal-buttons":  "row",
+	"modal-button":   "column",
+	"modal-overlay":  "md-overlay-red",
+	"hidden":         "hidden",
+	"modal-show":     "md-show",
+	"swapper-show":   "swapper-show",
+}
+
+var defaultIcons = map[string]string{
+	"add":    "fa fa-plus-circle fa-lg",
+	"delete": "fa fa-minus-circle fa-lg",
+	"edit":   "fa fa-pencil fa-lg",
+	"print":  "fa fa-print fa-lg",
+	"upload": "fa fa-upload fa-lg",
+	"file":   "fa fa-file-o",
+	"remove": "fa fa-times-circle",
+	"check":  "fa fa-check fa-lg",
+	"help":   "fa fa-question-circle",
+}
+
+// Class returns the classes for a part of the form, or "" if the theme has none
+func (DefaultTheme) Class(part string) string {
+	return defaultClasses[part]
+}
+
+// Icon returns the markup for a named icon
+func (DefaultTheme) Icon(name string) template.HTML {
+	cl, ok := defaultIcons[name]
+	if !ok {
+		cl = "fa fa-" + name
+	}
+	return template.HTML(fmt.Sprintf(`<i class="%s" aria-hidden="true"></i>`, template.HTMLEscapeString(cl)))
+}
+
+// TitleIcon returns the markup for the icon that the form was created with
+func (DefaultTheme) TitleIcon(icon string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<i class="fa %s fa-lg" style="font-size: 3rem" aria-hidden="true"></i>`,
+		template.HTMLEscapeString(icon)))
+}
+
+// Row returns the attributes for a row that holds span fields
+func (DefaultTheme) Row(span int) template.HTMLAttr {
+	return template.HTMLAttr(fmt.Sprintf(`data-row-span="%d"`, span))
+}
+
+// Field returns the attributes for a field of span columns, in a row of rowSpan
+func (DefaultTheme) Field(rowSpan int, span int) template.HTMLAttr {
+	return template.HTMLAttr(fmt.Sprintf(`data-field-span="%d"`, span))
+}
+
+var theme Theme = DefaultTheme{}
+
+// SetTheme changes the theme for all forms that dont have their own
+func SetTheme(t Theme) {
+	if t == nil {
+		t = DefaultTheme{}
+	}
+	theme = t
+}
+
+// CurrentTheme returns the theme that forms without their own are rendered with
+func CurrentTheme() Theme {
+	return theme
+}
+
+// Add or remove the theme's hidden class
+func showElement(t Theme, el dom.Element, show bool) {
+	setClass(el, t.Class("hidden"), !show)
+}
+
+// Add or remove a set of space separated classes
+func setClass(el dom.Element, classes string, on bool) {
+	if el == nil {
+		return
+	}
+	for _, c := range strings.Fields(classes) {
+		if on {
+			el.Class().Add(c)
+		} else {
+			el.Class().Remove(c)
+		}
+	}
+}
+
+// Whether the element has all of a set of space separated classes
+func hasClass(el dom.Element, classes string) bool {
+	for _, c := range strings.Fields(classes) {
+		if !el.Class().Contains(c) {
+			return false
+		}
+	}
+	return classes != ""
+}
+
+// Set the theme for this editform only
+func (f *EditForm) SetTheme(t Theme) *EditForm {
+	f.Theme = t
+	return f
+}
+
+// CurrentTheme returns the editform's own theme, or the global one
+func (f *EditForm) CurrentTheme() Theme {
+	if f.Theme != nil {
+		return f.Theme
+	}
+	return theme
+}
+
+// Set the theme for this listform only
+func (f *ListForm) SetTheme(t Theme) *ListForm {
+	f.Theme = t
+	return f
+}
+
+// CurrentTheme returns the listform's own theme, or the global one
+func (f *ListForm) CurrentTheme() Theme {
+	if f.Theme != nil {
+		return f.Theme
+	}
+	return theme
+}
+
+// Set the theme for this treeform only
+func (f *TreeForm) SetTheme(t Theme) *TreeForm {
+	f.Theme = t
+	return f
+}
+
+// CurrentTheme returns the treeform's own theme, or the global one
+func (f *TreeForm) CurrentTheme() Theme {
+	if f.Theme != nil {
+		return f.Theme
+	}
+	return theme
+}
@@ -3,7 +3,7 @@ package formulate
 import (
 	"github.com/go-humble/temple/temple"
 
-	"honnef.co/go/js/dom"
+	"github.com/steveoc64/formulate/dom"
 )
 
 // TreeCategories ...
@@ -40,6 +40,12 @@ type TreeForm struct {
 	NewRowCB    func(dom.Event)
 	PrintCB     func(dom.Event)
 	HasSetWidth bool
+	Shortcuts   []*Shortcut
+	UID         string
+	Theme       Theme
+	Root        dom.Element
+	listeners   listeners
+	rerender    func()
 }
 
 // New - Init a new treeform
@@ -88,6 +94,10 @@ func (f *TreeForm) Render(name string, selector string, data ...TreeData) {
 
 	f.Data = data
 	print("passed in treedata", data)
+	f.attach(selector)
+	f.rerender = func() {
+		f.Render(name, selector, data...)
+	}
 	renderTemplateT(f.generateTemplate(name), selector, f)
 	f.decorate(selector)
 }
@@ -96,49 +106,52 @@ func (f *TreeForm) Render(name string, selector string, data ...TreeData) {
 func (f *TreeForm) RenderCustom(name string, selector string, data ...TreeData) {
 
 	f.Data = data
+	f.attach(selector)
+	f.rerender = func() {
+		f.RenderCustom(name, selector, data...)
+	}
 	renderTemplate(name, selector, data)
 	f.decorate(selector)
 }
 
 func (f *TreeForm) decorate(selector string) {
 
-	w := dom.GetWindow()
-	doc := w.Document()
+	// drop the listeners from any previous render, so they dont stack up
+	f.listeners.removeAll()
 
 	// If there is a focusfield, then focus on it
-	if el := doc.QuerySelector("#focusme"); el != nil {
+	if el := f.query(f.id("focusme")); el != nil {
 		el.(*dom.HTMLInputElement).Focus()
 	}
 
 	// plug in cancel callbacks
 	if f.CancelCB != nil {
-		if el := doc.QuerySelector("#legend"); el != nil {
-			el.AddEventListener("click", false, f.CancelCB)
+		if el := f.query(f.id("legend")); el != nil {
+			f.listeners.add(el, "click", f.CancelCB)
 		}
 
-		if el := doc.QuerySelector(".md-close"); el != nil {
-			el.AddEventListener("click", false, f.CancelCB)
+		if el := f.query(".md-close"); el != nil {
+			f.listeners.add(el, "click", f.CancelCB)
 		}
 	}
 
 	if f.NewRowCB != nil {
-		if el := doc.QuerySelector(".data-add-btn"); el != nil {
-			el.AddEventListener("click", false, f.NewRowCB)
+		if el := f.query(".data-add-btn"); el != nil {
+			f.listeners.add(el, "click", f.NewRowCB)
 		}
 	}
 
 	if f.PrintCB != nil {
-		if el := doc.QuerySelector(".data-print-btn"); el != nil {
-			el.AddEventListener("click", false, f.PrintCB)
+		if el := f.query(".data-print-btn"); el != nil {
+			f.listeners.add(el, "click", f.PrintCB)
 		}
 	}
 
 	// Handlers on the table itself
-	sel := doc.QuerySelector(selector)
-	if el := sel.QuerySelector(".data-table"); el != nil {
+	if el := f.query(".data-table"); el != nil {
 
 		if f.RowCB != nil {
-			el.AddEventListener("click", false, func(evt dom.Event) {
+			f.listeners.add(el, "click", func(evt dom.Event) {
 				evt.PreventDefault()
 				td := evt.Target()
 				tr := td.ParentElement()
@@ -146,17 +159,12 @@ func (f *TreeForm) decorate(selector string) {
 				f.RowCB(key)
 			})
 		}
-
-		if f.CancelCB != nil {
-			el.AddEventListener("keyup", false, func(evt dom.Event) {
-				if evt.(*dom.KeyboardEvent).KeyCode == 27 {
-					evt.PreventDefault()
-					el.AddEventListener("click", false, f.CancelCB)
-				}
-			})
-		}
 	}
 
+	// plug in the keyboard shortcuts
+	f.decorateKeys()
+	activateButtons(&f.listeners, f.Root)
+
 }
 
 func (f *TreeForm) generateTemplate(name string) *temple.Template {
@@ -176,25 +184,25 @@ func (f *TreeForm) generateTemplate(name string) *temple.Template {
 		if doTitle {
 
 			src += `
-<div class="data-container">
-	<div class="row data-table-header">
-    <h3 class="column column-90" id="legend">
-      <i class="fa {{.Icon}} fa-lg" style="font-size: 3rem"></i> 
+<div class="{{.CurrentTheme.Class "container"}}">
+	<div class="{{.CurrentTheme.Class "header"}}">
+    <h3 class="{{.CurrentTheme.Class "title"}} legend" id="{{.UID}}-legend">
+      {{.CurrentTheme.TitleIcon .Icon}}
       {{.Title}}
     </h3>
 `
 			if f.NewRowCB != nil {
 				src += `
-    <div class="column col-center">
-      <i class="data-add-btn fa fa-plus-circle fa-lg no-print"></i>    
+    <div class="{{.CurrentTheme.Class "header-button"}}">
+      <span class="data-add-btn no-print" role="button" tabindex="0" aria-label="{{t "Add"}}">{{.CurrentTheme.Icon "add"}}</span>
     </div>    
 `
 			}
 
 			if f.PrintCB != nil {
 				src += `
-    <div class="column col-center">
-      <i class="data-print-btn fa fa-print fa-lg no-print"></i>    
+    <div class="{{.CurrentTheme.Class "header-button"}}">
+      <span class="data-print-btn no-print" role="button" tabindex="0" aria-label="{{t "Print"}}">{{.CurrentTheme.Icon "print"}}</span>
     </div>    
 `
 			}
@@ -0,0 +1,138 @@
+package formulate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rules are the checks that Validate makes on the value of a field
+type Rules struct {
+	Required  bool
+	Min       *float64 // for numbers
+	Max       *float64
+	MinLength int // in characters, for text
+	MaxLength int
+	Pattern   string // a regexp that text must contain a match for
+	Message   string // shown instead of the built in message when a check fails
+}
+
+// SetRules sets the validation rules for a field
+func (f *EditForm) SetRules(model string, rules Rules) *EditForm {
+	field := f.GetField(model)
+	if field == nil {
+		print("SetRules: no field", model)
+		return f
+	}
+	field.Rules = &rules
+	return f
+}
+
+// Validate checks the values in the form against the rules for each field, and that
+// masked fields are filled in, and shows a message on each field that fails. It returns the messages by model,
+// which is empty if everything is valid
+func (f *EditForm) Validate() map[string]string {
+	errs := map[string]string{}
+	for _, row := range f.Rows {
+		for _, field := range row.Fields {
+			if (field.Rules == nil && field.Mask == "") || field.Readonly || field.Model == "" {
+				continue
+			}
+			value := f.fieldValue(field)
+			msg := ""
+			if field.Rules != nil {
+				msg = field.Rules.check(field.Type, value)
+			}
+			if msg == "" && field.Mask != "" && value != "" && !field.maskComplete(value) {
+				msg = field.maskMessage()
+			}
+			if msg != "" {
+				errs[field.Model] = msg
+				f.SetError(field.Model, msg)
+			} else {
+				f.ClearError(field.Model)
+			}
+		}
+	}
+	return errs
+}
+
+// The value of a field as it stands in the DOM, with "" for nothing chosen
+func (f *EditForm) fieldValue(field *EditField) string {
+	switch field.Type {
+	case "radio":
+		for _, el := range f.findAll(`[name="` + field.Model + `"]`) {
+			if el.Checked() {
+				return el.Value()
+			}
+		}
+		return ""
+	case "files":
+		if len(field.Files) == 0 {
+			return ""
+		}
+		return strconv.Itoa(len(field.Files))
+	case "signature":
+		return field.Value
+	}
+	el := f.find(`[name="` + field.Model + `"]`)
+	if el == nil {
+		return ""
+	}
+	switch field.Type {
+	case "checkbox":
+		if el.Checked() {
+			return "true"
+		}
+		return ""
+	case "select":
+		idx := el.SelectedIndex()
+		if idx < 0 || idx >= len(field.Options) || field.Options[idx].Display == "" {
+			return ""
+		}
+		return field.Options[idx].Display
+	}
+	return strings.TrimSpace(el.Value())
+}
+
+// Check a value against the rules, returning the message for the first that fails
+func (r *Rules) check(fieldType string, value string) string {
+	msg := ""
+	switch {
+	case value == "":
+		if r.Required {
+			msg = T("This field is required")
+		}
+	case fieldType == "number":
+		v, err := strconv.ParseFloat(value, 64)
+		switch {
+		case err != nil:
+			msg = T("Must be a number")
+		case r.Min != nil && v < *r.Min:
+			msg = fmt.Sprintf(T("Must be at least %v"), *r.Min)
+		case r.Max != nil && v > *r.Max:
+			msg = fmt.Sprintf(T("Must be no more than %v"), *r.Max)
+		}
+	default:
+		n := utf8.RuneCountInString(value)
+		switch {
+		case r.MinLength > 0 && n < r.MinLength:
+			msg = fmt.Sprintf(T("Must be at least %d characters"), r.MinLength)
+		case r.MaxLength > 0 && n > r.MaxLength:
+			msg = fmt.Sprintf(T("Must be no more than %d characters"), r.MaxLength)
+		case r.Pattern != "":
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				print("Rules: bad pattern", r.Pattern, err.Error())
+			} else if !re.MatchString(value) {
+				msg = T("Is not in the right format")
+			}
+		}
+	}
+	if msg != "" && r.Message != "" {
+		return T(r.Message)
+	}
+	return msg
+}
@@ -0,0 +1,245 @@
+package formulate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SetValue sets the value of a field on the rendered form, which may be on a swapper
+// panel. The value is what Render would load from the data for the field, such as an
+// option key for a select, a bool for a checkbox, a time.Time for a date, or a
+// FileField for a photo. A nil value clears the field
+func (f *EditForm) SetValue(model string, value interface{}) error {
+	field, err := f.valueField("SetValue", model)
+	if err != nil {
+		return err
+	}
+
+	// display mode shows the value as text, and a signature is drawn on its pad,
+	// so paint the whole form again for those
+	repaint := f.DisplayMode || field.Type == "signature"
+	var open map[*Swapper]int
+	if repaint {
+		f.keepValues()
+		open = f.openPanels()
+	}
+
+	_, fromMap := dataMap(f.data)
+	f.setFieldValue(field, reflect.ValueOf(value), fromMap)
+	if repaint {
+		f.paintAgain(open)
+	} else {
+		f.paintValue(field)
+	}
+	f.fieldChanged(field)
+	return nil
+}
+
+// GetValue reads the value of a field on the rendered form, which may be on a swapper
+// panel, as Bind would read it. That is an option key for a select, or the enum value
+// if it has one, a bool for a checkbox, an int or float64 for a number, a time.Time for
+// a date, []FileField for files, FileField for a photo, and a string for the others.
+// An empty number or date is nil, and an error is returned if the text cannot be read
+func (f *EditForm) GetValue(model string) (interface{}, error) {
+	field, err := f.valueField("GetValue", model)
+	if err != nil {
+		return nil, err
+	}
+	f.keepValue(field)
+	return f.typedValue(field)
+}
+
+// The value kept on a field, as Bind would read it
+func (f *EditForm) typedValue(field *EditField) (interface{}, error) {
+	model := field.Model
+	switch field.Type {
+	case "checkbox":
+		return field.Checked || field.Value != "", nil
+	case "select", "radio":
+		key, err := strconv.Atoi(field.Value)
+		if err != nil {
+			return nil, nil
+		}
+		for _, o := range field.Options {
+			if o.Key == key {
+				return field.optionValue(key), nil
+			}
+		}
+		return nil, nil
+	case "groupselect":
+		return field.Selected, nil
+	case "files":
+		return append([]FileField{}, field.Files...), nil
+	case "photo":
+		ff := f.photoFile(field)
+		if ff.Data == "" {
+			ff.Data = field.Value
+		}
+		return ff, nil
+	case "signature":
+		return field.Value, nil
+	}
+
+	text := strings.TrimSpace(field.Value)
+	switch field.Type {
+	case "number":
+		if text == "" {
+			return nil, nil
+		}
+		if field.IsFloat {
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("formulate: %s is not a number: %q", model, text)
+			}
+			return v, nil
+		}
+		v, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("formulate: %s is not a whole number: %q", model, text)
+		}
+		return v, nil
+	case "date", "datetime-local":
+		if text == "" {
+			return nil, nil
+		}
+		t, ok := parseDate(text)
+		if !ok {
+			return nil, fmt.Errorf("formulate: %s is not a date: %q", model, text)
+		}
+		return t, nil
+	case "text", "textarea":
+		if field.ListOf != "" {
+			return listItems(text, field.ListOf), nil
+		}
+		return field.bindText(text), nil
+	}
+	return text, nil
+}
+
+// Set the value kept on a field from a value in the data, as Render loads it.
+// Values from map data, such as decoded JSON, are loaded as Render loads map data
+func (f *EditForm) setFieldValue(field *EditField, v reflect.Value, fromMap bool) {
+	field.Value = ""
+	field.Checked = false
+	field.Files = nil
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch {
+	case field.Type == "photo":
+		// a photo is a data URL, in a string or a FileField
+		field.Value = getSignature(v)
+	case fromMap && v.IsValid():
+		f.loadMapValue(field, v.Interface())
+	case field.Type == "select" || field.Type == "radio":
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		key := -1
+		if v.IsValid() {
+			key = enumKey(field, v.Interface())
+		}
+		field.selectKey(key)
+		if f.DisplayMode {
+			field.Display = displayValue(field, v)
+		}
+	default:
+		f.loadValue(field, v)
+	}
+	if field.Type == "groupselect" {
+		field.Selected, _ = strconv.Atoi(field.Value)
+	}
+	field.maskValue()
+}
+
+// The field of the model, for SetValue and GetValue
+func (f *EditForm) valueField(method string, model string) (*EditField, error) {
+	if !f.IsRendered {
+		return nil, fmt.Errorf("formulate: %s(%s) called before the form is rendered", method, model)
+	}
+	field := f.GetField(model)
+	if field == nil {
+		return nil, fmt.Errorf("formulate: there is no field for %s", model)
+	}
+	if f.access(field) == Hidden {
+		return nil, fmt.Errorf("formulate: the policy hides %s", model)
+	}
+	return field, nil
+}
+
+// Write the value kept on a field into the DOM
+func (f *EditForm) paintValue(field *EditField) {
+	name := `[name="` + field.Model + `"]`
+	switch field.Type {
+	case "div", "swapper", "button":
+		return
+	case "files":
+		// the file list is drawn with the browser DOM
+		if f.Root != nil {
+			f.paintFiles(field)
+		}
+		return
+	case "photo":
+		img := f.find(`[name="` + field.Model + `Preview"]`)
+		if img == nil {
+			return
+		}
+		if field.Value == "" {
+			img.RemoveAttribute("src")
+		} else {
+			img.SetAttribute("src", field.Value)
+		}
+		hidden := f.CurrentTheme().Class("hidden")
+		setNodeClass(img, hidden, field.Value == "")
+		setNodeClass(f.find(`[name="`+field.Model+`PreviewHint"]`), hidden, field.Value == "")
+		return
+	case "radio":
+		for _, el := range f.findAll(name) {
+			el.SetChecked(el.Value() == field.Value)
+		}
+		return
+	}
+	el := f.find(name)
+	if el == nil {
+		return
+	}
+	switch field.Type {
+	case "checkbox":
+		el.SetChecked(field.Checked || field.Value != "")
+	case "groupselect":
+		el.SetValue(strconv.Itoa(field.Selected))
+	default:
+		el.SetValue(field.Value)
+	}
+}
+
+// SetValue sets the value of a field on the panel, as EditForm.SetValue does
+func (p *Panel) SetValue(model string, value interface{}) error {
+	if err := p.valueField(model); err != nil {
+		return err
+	}
+	return p.form.SetValue(model, value)
+}
+
+// GetValue reads the value of a field on the panel, as EditForm.GetValue does
+func (p *Panel) GetValue(model string) (interface{}, error) {
+	if err := p.valueField(model); err != nil {
+		return nil, err
+	}
+	return p.form.GetValue(model)
+}
+
+// Check that the model is a field of the panel, and the panel is on a rendered form
+func (p *Panel) valueField(model string) error {
+	if p.form == nil {
+		return fmt.Errorf("formulate: panel %s is not on a rendered form", p.Name)
+	}
+	for _, field := range p.fields() {
+		if field.Model == model {
+			return nil
+		}
+	}
+	return fmt.Errorf("formulate: there is no field for %s on panel %s", model, p.Name)
+}
@@ -0,0 +1,112 @@
+package formulate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetValueGetValue(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Hours: 2}
+	f := newTestForm(job)
+	if err := f.SetValue("Name", "too soon"); err == nil {
+		t.Error("SetValue before Render did not fail")
+	}
+	f.Render("edit-form", "#form", job)
+
+	due := time.Date(2017, 3, 14, 0, 0, 0, 0, time.UTC)
+	sets := []struct {
+		model string
+		value interface{}
+		want  interface{}
+	}{
+		{"Name", "Pump rebuild", "Pump rebuild"},
+		{"Notes", "Seals\nValves", "Seals\nValves"},
+		{"Status", 3, 3},
+		{"Group", 2, 2},
+		{"Urgent", true, true},
+		{"Priority", 2, 2},
+		{"Hours", 5, 5},
+		{"Rate", 12.5, 12.5},
+		{"Due", due, due},
+		{"Due", &due, due},
+		{"Hours", nil, nil},
+		{"Site", "Depot", "Depot"},
+		{"OnSite", true, true},
+		{"Visits", 4, 4},
+	}
+	for _, s := range sets {
+		if err := f.SetValue(s.model, s.value); err != nil {
+			t.Errorf("SetValue(%s): %v", s.model, err)
+			continue
+		}
+		got, err := f.GetValue(s.model)
+		if err != nil || got != s.want {
+			t.Errorf("GetValue(%s) = %v (%T), %v, want %v", s.model, got, got, err, s.want)
+		}
+	}
+
+	// the values are in the DOM, so Bind reads them as well
+	if n := fakeField(t, doc, `[name="Rate"]`); n.Value() != "12.50" {
+		t.Errorf("Rate input = %q", n.Value())
+	}
+	if n := fakeField(t, doc, `[name="Priority"][value="2"]`); !n.Checked() {
+		t.Error("the Priority radio was not checked")
+	}
+	bound := &testJob{}
+	f.Bind(bound)
+	if bound.Name != "Pump rebuild" || bound.Status != 3 || !bound.Urgent || bound.Priority != 2 || bound.Due == nil || !bound.Due.Equal(due) {
+		t.Errorf("bound %+v", *bound)
+	}
+
+	files := []FileField{{Filename: "quote.pdf", Data: "data:application/pdf;base64,AA=="}}
+	f.SetValue("Attachments", files)
+	if got, _ := f.GetValue("Attachments"); len(got.([]FileField)) != 1 {
+		t.Errorf("Attachments = %v", got)
+	}
+
+	fakeField(t, doc, `[name="Hours"]`).SetValue("lots")
+	if _, err := f.GetValue("Hours"); err == nil {
+		t.Error("GetValue read a number from text")
+	}
+	if err := f.SetValue("Nothing", 1); err == nil {
+		t.Error("SetValue of an unknown model did not fail")
+	}
+}
+
+func TestPanelSetValue(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+
+	panel := f.GetField("").Swapper.Panels[0]
+	if err := panel.SetValue("Visits", 7); err != nil {
+		t.Fatal(err)
+	}
+	if n := fakeField(t, doc, `[name="Visits"]`); n.Value() != "7" {
+		t.Errorf("Visits input = %q", n.Value())
+	}
+	if got, err := panel.GetValue("Visits"); err != nil || got != 7 {
+		t.Errorf("GetValue(Visits) = %v, %v", got, err)
+	}
+	if _, err := panel.GetValue("Name"); err == nil {
+		t.Error("the panel read a field that is not on it")
+	}
+}
+
+func TestSetValueInDisplayMode(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Hours: 2}
+	f := newTestForm(job)
+	f.Render("edit-form", "#form", job)
+	f.SetDisplayMode(true)
+
+	f.SetValue("Hours", 1500)
+	if n := fakeField(t, doc, `#`+f.UID+`-Hours`); n.Value() != locale.FormatNumber(1500, 0) {
+		t.Errorf("Hours shows %q", n.Value())
+	}
+	if got, _ := f.GetValue("Hours"); got != 1500 {
+		t.Errorf("GetValue(Hours) = %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package formulate
+
+import (
+	"path"
+	"reflect"
+
+	"github.com/steveoc64/formulate/dom"
+)
+
+type fieldWatcher struct {
+	model string
+	cb    func(old, new interface{})
+}
+
+// OnFieldChange calls cb with the old and new values whenever the value of a field
+// changes, whether it is changed on the form or by SetValue or Refresh. The values are
+// those that GetValue returns. The model can have wildcards, such as "*" or "Address.*",
+// and the fields can be on swapper panels
+func (f *EditForm) OnFieldChange(model string, cb func(old, new interface{})) *EditForm {
+	wired := map[*EditField]bool{}
+	if f.IsRendered {
+		for _, field := range f.allFields() {
+			wired[field] = f.listensTo(field)
+		}
+	}
+	f.watchers = append(f.watchers, fieldWatcher{model: model, cb: cb})
+	if f.IsRendered {
+		for _, field := range f.allFields() {
+			if f.watches(field) && !wired[field] {
+				f.listenTo(field)
+			}
+		}
+	}
+	return f
+}
+
+// Whether the model matches the model of a subscription, which can have wildcards
+func modelMatches(pattern string, model string) bool {
+	ok, _ := path.Match(pattern, model)
+	return ok || pattern == model
+}
+
+// Whether any subscription is for the field
+func (f *EditForm) watches(field *EditField) bool {
+	if field.Model == "" || field.Type == "swapper" || field.Type == "div" {
+		return false
+	}
+	for _, w := range f.watchers {
+		if modelMatches(w.model, field.Model) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the form listens for changes to the field, to tell the subscriptions or to bind it live
+func (f *EditForm) listensTo(field *EditField) bool {
+	return f.watches(field) || f.bindsLive(field)
+}
+
+// Listen for changes to each field that has a subscription, or is bound live
+func (f *EditForm) decorateChanges() {
+	for _, field := range f.allFields() {
+		if f.listensTo(field) {
+			f.listenTo(field)
+		}
+	}
+}
+
+// Remember the value of the field, and listen for changes on every element of it,
+// such as each radio button of a group. A live field is bound as it is typed into
+func (f *EditForm) listenTo(field *EditField) {
+	if f.access(field) == Hidden {
+		return
+	}
+	if f.watches(field) {
+		if f.watched == nil {
+			f.watched = map[*EditField]interface{}{}
+		}
+		if _, ok := f.watched[field]; !ok {
+			f.watched[field], _ = f.typedValue(field)
+		}
+	}
+	events := []string{"change"}
+	if f.bindsLive(field) {
+		events = append(events, "input")
+	}
+	for _, el := range f.findAll(`[name="` + field.Model + `"]`) {
+		for _, event := range events {
+			f.listeners.addNode(el, event, func(dom.Event) {
+				f.fieldChanged(field)
+			})
+		}
+	}
+}
+
+// The field has been changed on the form or by SetValue, so bind it if the form
+// is live, and tell the subscriptions
+func (f *EditForm) fieldChanged(field *EditField) {
+	f.bindLive(field)
+	f.tellWatchers(field)
+}
+
+// Tell the subscriptions for the field if its value has changed since they were last told
+func (f *EditForm) tellWatchers(field *EditField) {
+	if !f.watches(field) || f.access(field) == Hidden {
+		return
+	}
+	f.keepValue(field)
+	value, err := f.typedValue(field)
+	if err != nil {
+		// such as a number that cannot be read yet
+		return
+	}
+	old, ok := f.watched[field]
+	if ok && reflect.DeepEqual(old, value) {
+		return
+	}
+	if f.watched == nil {
+		f.watched = map[*EditField]interface{}{}
+	}
+	f.watched[field] = value
+	for _, w := range f.watchers {
+		if modelMatches(w.model, field.Model) {
+			w.cb(old, value)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package formulate
+
+import "testing"
+
+type testChange struct {
+	model    string
+	old, new interface{}
+}
+
+func TestOnFieldChange(t *testing.T) {
+	doc := setupFakeDOM(t, `<div id="form"></div>`)
+	job := &testJob{Name: "Pump service", Status: 1, Priority: 1, Hours: 2, Visits: 1}
+	f := newTestForm(job)
+	changes := []testChange{}
+	watch := func(model string) func(old, new interface{}) {
+		return func(old, new interface{}) {
+			changes = append(changes, testChange{model, old, new})
+		}
+	}
+	f.OnFieldChange("Priority", watch("Priority")).
+		OnFieldChange("Hours", watch("Hours")).
+		OnFieldChange("Vis*", watch("Vis*"))
+	f.Render("edit-form", "#form", job)
+
+	change := func(n *fakeNode) {
+		for _, cb := range n.listeners["change"] {
+			cb(nil)
+		}
+	}
+
+	// any radio of the group tells of the change
+	radio := fakeField(t, doc, `[name="Priority"][value="3"]`)
+	radio.SetChecked(true)
+	change(radio)
+	// a number that cannot be read yet, then one that can
+	hours := fakeField(t, doc, `[name="Hours"]`)
+	hours.SetValue("")
+	change(hours)
+	hours.SetValue("6")
+	change(hours)
+	change(hours)
+	// a field on a swapper panel, by wildcard
+	visits := fakeField(t, doc, `[name="Visits"]`)
+	visits.SetValue("3")
+	change(visits)
+	// and changes made by SetValue
+	f.SetValue("Visits", 4)
+	f.SetValue("Visits", 4)
+
+	want := []testChange{
+		{"Priority", 1, 3},
+		{"Hours", 2, nil},
+		{"Hours", nil, 6},
+		{"Vis*", 1, 3},
+		{"Vis*", 3, 4},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %v, want %v", i, changes[i], want[i])
+		}
+	}
+
+	// a subscription after Render is wired up straight away
+	changes = nil
+	f.OnFieldChange("Name", watch("Name"))
+	name := fakeField(t, doc, `[name="Name"]`)
+	name.SetValue("Pump rebuild")
+	change(name)
+	if len(changes) != 1 || changes[0] != (testChange{"Name", "Pump service", "Pump rebuild"}) {
+		t.Errorf("changes = %v", changes)
+	}
+	if n := len(hours.listeners["change"]); n != 1 {
+		t.Errorf("Hours has %d change listeners", n)
+	}
+}